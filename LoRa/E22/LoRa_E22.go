@@ -1,7 +1,10 @@
 package E22
 
 import (
-
+  "bytes"
+  "context"
+  "e22config/LoRa"
+  "time"
 )
 
 var (
@@ -14,6 +17,7 @@ var (
   COMMAND_GET_REGISTER                = [...]byte{0xC1}
   COMMAND_SET_TEMPORARY_REGISTER      = [...]byte{0xC2}
   COMMAND_WIRELESS_CONFIG             = [...]byte{0xCF, 0xCF}
+  COMMAND_READ_RSSI                   = [...]byte{0xC0, 0xC1, 0xC2, 0xC3}
 
   RESPONSE_WRONG_FORMAT               = [...]byte{0xFF, 0xFF, 0xFF}
 
@@ -34,6 +38,9 @@ var (
   REGISTER_PID5                       = [...]byte{0x85}
   REGISTER_PID6                       = [...]byte{0x86}
 
+  RSSI_NOISE                          = [...]byte{0x00} // current channel noise
+  RSSI_LAST                           = [...]byte{0x01} // last received packet
+
   BROADCAST                           = [...]byte{0xFF} // ADDH, ADDL
 
   UART_BAUD_1200 byte                 = 0x00
@@ -109,14 +116,177 @@ var (
   MASK_WOR_CYCLE byte                 = 0x07
 )
 
+const (
+  DEFAULT_TIMEOUT = time.Second
+  WIRELESS_TIMEOUT = 5 * time.Second
+)
+
+// Transport is a link to the module UART: serial port, socket, etc.
+type Transport = LoRa.Transport
+
+// Device drives the module through any Transport
 type Device struct {
-  IsOpen bool
+  LoRa.Port
+  Timeout time.Duration   // response timeout, DEFAULT_TIMEOUT (WIRELESS_TIMEOUT) if zero
+  Wireless bool           // configure remote module over the air (COMMAND_WIRELESS_CONFIG)
+  Model *Model            // module variant, DEFAULT_MODEL if nil
+  Pins LoRa.ModeController  // M0/M1 control, modes are set by jumpers if nil
+  Config *Config          // active configuration of local module for data transfer, DefaultConfig() if nil
+  Retry LoRa.RetryPolicy  // repeats failed commands, single attempt if zero
+}
+
+func (d *Device) model() *Model {
+  if d.Model != nil {
+    return d.Model
+  }
+  return DEFAULT_MODEL
+}
+
+func (d *Device) timeout() time.Duration {
+  if d.Timeout > 0 {
+    return d.Timeout
+  }
+  if d.Wireless {
+    return WIRELESS_TIMEOUT
+  }
+  return DEFAULT_TIMEOUT
+}
+
+// isRejected reports if local or remote module answered with FF FF FF
+func isRejected(response []byte) bool {
+  return IsWrongFormat(response) ||
+    IsWrongFormat(bytes.TrimPrefix(response, COMMAND_WIRELESS_CONFIG[:]))
+}
+
+// register sends register command to local or remote module and returns
+// data part of the response
+func (d *Device) register(ctx context.Context, command []byte, address, length byte, data []byte) ([]byte, error) {
+  prefix := []byte{}
+  if d.Wireless {
+    prefix = COMMAND_WIRELESS_CONFIG[:]
+  }
+  expected := len(prefix) + HEADER_LENGTH + int(length)
+  complete := func(response []byte) bool {
+    return len(response) >= expected || isRejected(response)
+  }
+  // remote module is reached in normal mode, local one is configured
+  // in configuration mode
+  mode := LoRa.MODE_CONFIGURATION
+  if d.Wireless {
+    mode = LoRa.MODE_NORMAL
+  }
+  var args []byte
+  err := LoRa.WithMode(d.Pins, mode, func() error {
+    return d.Retry.Do(ctx, "Register command", func() error {
+      attempt, cancel := context.WithTimeout(ctx, d.timeout())
+      defer cancel()
+      response, err := d.Command(attempt, complete, prefix, command, []byte{address, length}, data)
+      if err != nil {
+        return err
+      }
+      if len(prefix) > 0 && !IsWrongFormat(response) {
+        if !bytes.HasPrefix(response, prefix) {
+          if len(response) < len(prefix) {
+            return &LoRa.ShortFrameError{Expected: expected, Received: len(response)}
+          }
+          return &LoRa.FrameError{Field: "wireless prefix", Expected: int(prefix[0]), Received: int(response[0])}
+        }
+        response = response[len(prefix):]
+      }
+      args, err = ParseResponse(response, address, length)
+      return err
+    })
+  })
+  return args, err
+}
+
+// ReadRegisters reads length registers starting from address
+func (d *Device) ReadRegisters(address, length byte) ([]byte, error) {
+  if err := checkRange(address, int(length)); err != nil {
+    return nil, err
+  }
+  return d.register(context.Background(), COMMAND_GET_REGISTER[:], address, length, nil)
+}
+
+// WriteRegisters writes data to registers starting from address (saved in flash)
+func (d *Device) WriteRegisters(address byte, data []byte) error {
+  return d.setRegisters(COMMAND_SET_REGISTER[:], address, data)
+}
+
+// ApplyRegisters writes data to registers starting from address; values are
+// not saved and module reverts them on power cycle
+func (d *Device) ApplyRegisters(address byte, data []byte) error {
+  return d.setRegisters(COMMAND_SET_TEMPORARY_REGISTER[:], address, data)
+}
+
+func (d *Device) setRegisters(command []byte, address byte, data []byte) error {
+  if err := checkRange(address, len(data)); err != nil {
+    return err
+  }
+  _, err := d.register(context.Background(), command, address, byte(len(data)), data)
+  return err
 }
 
-func (d *Device) Open(name string) error {
-  return nil
+// config returns active configuration of local module
+func (d *Device) config() Config {
+  if d.Config != nil {
+    return *d.Config
+  }
+  return DefaultConfig()
 }
 
-func (d *Device) Close() {
+// activate remembers configuration of local module
+func (d *Device) activate(cfg Config) {
+  if !d.Wireless {
+    d.Config = &cfg
+  }
+}
+
+// ReadConfig reads and decodes configuration registers; configuration of
+// local module becomes active one
+func (d *Device) ReadConfig() (Config, error) {
+  args, err := d.ReadRegisters(GET_CONFIG[0], GET_CONFIG[1])
+  if err != nil {
+    return Config{}, err
+  }
+  cfg, err := d.model().UnmarshalConfig(args)
+  if err == nil {
+    d.activate(cfg)
+  }
+  return cfg, err
+}
+
+// WriteConfig encodes and saves configuration registers
+func (d *Device) WriteConfig(cfg Config) error {
+  args, err := d.model().MarshalConfig(cfg)
+  if err != nil {
+    return err
+  }
+  if err = d.WriteRegisters(SET_CONFIG[0], args); err == nil {
+    d.activate(cfg)
+  }
+  return err
+}
+
+// ApplyConfig encodes and applies configuration registers without saving
+func (d *Device) ApplyConfig(cfg Config) error {
+  args, err := d.model().MarshalConfig(cfg)
+  if err != nil {
+    return err
+  }
+  if err = d.ApplyRegisters(SET_CONFIG[0], args); err == nil {
+    d.activate(cfg)
+  }
+  return err
+}
 
+// ReadProductInfo reads product information registers (PID0..PID6)
+func (d *Device) ReadProductInfo() (ProductInfo, error) {
+  info := ProductInfo{}
+  args, err := d.ReadRegisters(GET_PRODUCT_INFO[0], GET_PRODUCT_INFO[1])
+  if err != nil {
+    return info, err
+  }
+  err = info.UnmarshalRegisters(args)
+  return info, err
 }
@@ -0,0 +1,82 @@
+package E22
+
+import (
+  "bytes"
+  "e22config/LoRa"
+  "e22config/LoRa/GPIO"
+  "net"
+  "testing"
+  "time"
+)
+
+// fakeModule opens device on a pipe answered by respond; commands are
+// passed to respond as written
+func fakeModule(t *testing.T, respond func(command []byte) []byte) *Device {
+  host, module := net.Pipe()
+  go func() {
+    buffer := make([]byte, 512)
+    for {
+      n, err := module.Read(buffer)
+      if err != nil {
+        return
+      }
+      response := respond(append([]byte{}, buffer[:n]...))
+      if len(response) == 0 {
+        continue
+      }
+      if _, err := module.Write(response); err != nil {
+        return
+      }
+    }
+  }()
+  d := &Device{Timeout: 200 * time.Millisecond}
+  if err := d.Open(host); err != nil {
+    t.Fatal(err)
+  }
+  t.Cleanup(func() {
+    d.Close()
+    module.Close()
+  })
+  return d
+}
+
+// registerReply answers register command with zero registers
+func registerReply(command []byte) []byte {
+  prefix := []byte{}
+  if bytes.HasPrefix(command, COMMAND_WIRELESS_CONFIG[:]) {
+    prefix = COMMAND_WIRELESS_CONFIG[:]
+    command = command[len(prefix):]
+  }
+  length := command[2]
+  response := append(append([]byte{}, prefix...), COMMAND_GET_REGISTER[0], command[1], length)
+  return append(response, make([]byte, length)...)
+}
+
+func TestRegisterModes(t *testing.T) {
+  tests := []struct {
+    wireless bool
+    mode LoRa.Mode
+  }{
+    {false, LoRa.MODE_CONFIGURATION},
+    {true, LoRa.MODE_NORMAL},
+  }
+  for _, test := range tests {
+    pins := &GPIO.FakeModePins{Current: LoRa.MODE_WOR}
+    modes := []LoRa.Mode{}
+    d := fakeModule(t, func(command []byte) []byte {
+      modes = append(modes, pins.Mode())
+      return registerReply(command)
+    })
+    d.Pins = pins
+    d.Wireless = test.wireless
+    if _, err := d.ReadRegisters(REGISTER_ADDH[0], 3); err != nil {
+      t.Fatalf("wireless %v: %v", test.wireless, err)
+    }
+    if len(modes) != 1 || modes[0] != test.mode {
+      t.Fatalf("wireless %v: command sent in %v, expected %s", test.wireless, modes, test.mode)
+    }
+    if pins.Mode() != LoRa.MODE_WOR {
+      t.Fatalf("wireless %v: %s mode left", test.wireless, pins.Mode())
+    }
+  }
+}
@@ -0,0 +1,86 @@
+package E22
+
+import (
+  "bytes"
+  "fmt"
+  "strings"
+)
+
+// Annotate describes UART chunk of E22 traffic for capture viewers; tx is
+// host to module direction. Chunks are described as they come, frames split
+// by the transport show up as data
+func Annotate(data []byte, tx bool) string {
+  if tx {
+    if bytes.HasPrefix(data, COMMAND_WIRELESS_CONFIG[:]) {
+      return "wireless " + Annotate(data[len(COMMAND_WIRELESS_CONFIG):], tx)
+    }
+    if len(data) == len(COMMAND_READ_RSSI) + 2 && bytes.HasPrefix(data, COMMAND_READ_RSSI[:]) {
+      return "RSSI query"
+    }
+    if len(data) < HEADER_LENGTH {
+      return fmt.Sprintf("data %d bytes", len(data))
+    }
+    operation := map[byte]string{
+      COMMAND_SET_REGISTER[0]: "write",
+      COMMAND_GET_REGISTER[0]: "read",
+      COMMAND_SET_TEMPORARY_REGISTER[0]: "apply",
+    }[data[0]]
+    if operation == "" || checkRange(data[1], int(data[2])) != nil {
+      return fmt.Sprintf("data %d bytes", len(data))
+    }
+    return fmt.Sprintf("%s %s", operation, registerRange(data[1], data[2]))
+  }
+
+  if bytes.HasPrefix(data, COMMAND_WIRELESS_CONFIG[:]) {
+    return "wireless " + Annotate(data[len(COMMAND_WIRELESS_CONFIG):], tx)
+  }
+  if IsWrongFormat(data) {
+    return "rejected (wrong format)"
+  }
+  if len(data) < HEADER_LENGTH || data[0] != COMMAND_GET_REGISTER[0] ||
+    len(data) != HEADER_LENGTH + int(data[2]) {
+    return fmt.Sprintf("data %d bytes", len(data))
+  }
+  address, length := data[1], data[2]
+  args := data[HEADER_LENGTH:]
+  text := "registers " + registerRange(address, length)
+  switch {
+  case address == GET_CONFIG[0] && int(length) >= int(REGISTER_CRYPT_H[0]):
+    if cfg, err := DEFAULT_MODEL.UnmarshalConfig(args); err == nil {
+      text += fmt.Sprintf(": address %d, NETID %d, UART %d %s, air %d bps, channel %d",
+        cfg.Address, cfg.NetID, cfg.UARTRate, cfg.UARTParity, cfg.AirRate, cfg.Channel)
+    }
+  case address == GET_PRODUCT_INFO[0] && length == GET_PRODUCT_INFO[1]:
+    info := ProductInfo{}
+    if info.UnmarshalRegisters(args) == nil {
+      text += fmt.Sprintf(": PID % X", info.Raw)
+    }
+  case address == RSSI_NOISE[0] && length == RSSI_LENGTH:
+    text += fmt.Sprintf(" or RSSI: noise %s, last %s", rssiText(args[0]), rssiText(args[1]))
+  }
+  return text
+}
+
+func rssiText(value byte) string {
+  if value == 0 {
+    return "none"
+  }
+  return fmt.Sprintf("%d dBm", RSSIdBm(value))
+}
+
+// registerRange names register range, e.g. "00H..06H (ADDH..REG3)"
+func registerRange(address, length byte) string {
+  last := address + length - 1
+  if length == 1 {
+    if name := RegisterName(address); name != "" {
+      return fmt.Sprintf("%02XH (%s)", address, name)
+    }
+    return fmt.Sprintf("%02XH", address)
+  }
+  names := []string{RegisterName(address), RegisterName(last)}
+  text := fmt.Sprintf("%02XH..%02XH", address, last)
+  if names[0] != "" && names[1] != "" {
+    text += " (" + strings.Join(names, "..") + ")"
+  }
+  return text
+}
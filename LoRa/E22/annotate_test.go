@@ -0,0 +1,32 @@
+package E22
+
+import (
+  "testing"
+)
+
+func TestAnnotate(t *testing.T) {
+  // factory settings
+  config := []byte{0xC1, 0x00, 0x07, 0x00, 0x00, 0x00, 0x62, 0x00, 0x17, 0x00}
+  for _, test := range []struct {
+    data []byte
+    tx bool
+    text string
+  }{
+    {[]byte{0xC1, 0x00, 0x07}, true, "read 00H..06H (ADDH..REG3)"},
+    {[]byte{0xC0, 0x00, 0x09, 0, 0, 0, 0x62, 0, 0x17, 0, 0, 0}, true, "write 00H..08H (ADDH..CRYPT_L)"},
+    {[]byte{0xC2, 0x05, 0x01, 0x17}, true, "apply 05H (REG2)"},
+    {[]byte{0xCF, 0xCF, 0xC1, 0x80, 0x07}, true, "wireless read 80H..86H (PID0..PID6)"},
+    {[]byte{0xC0, 0xC1, 0xC2, 0xC3, 0x00, 0x02}, true, "RSSI query"},
+    {[]byte{0xC5, 0x00, 0x01}, true, "data 3 bytes"},
+    {[]byte{0xFF, 0xFF, 0xFF}, false, "rejected (wrong format)"},
+    {[]byte{0xCF, 0xCF, 0xFF, 0xFF, 0xFF}, false, "wireless rejected (wrong format)"},
+    {config, false, "registers 00H..06H (ADDH..REG3): address 0, NETID 0, UART 9600 8N1, air 2400 bps, channel 23"},
+    {[]byte{0xC1, 0x00, 0x02, 0xA0, 0x00}, false, "registers 00H..01H (ADDH..ADDL) or RSSI: noise -96 dBm, last none"},
+    {[]byte{0xC1, 0x80, 0x07, 0, 0x22, 0x28, 0x1E, 1, 2, 0}, false, "registers 80H..86H (PID0..PID6): PID 00 22 28 1E 01 02 00"},
+    {[]byte("hi"), false, "data 2 bytes"},
+  } {
+    if text := Annotate(test.data, test.tx); text != test.text {
+      t.Fatalf("% X annotated as %q, expected %q", test.data, text, test.text)
+    }
+  }
+}
@@ -0,0 +1,233 @@
+package E22
+
+import (
+  "e22config/LoRa"
+  "fmt"
+)
+
+const (
+  CONFIG_LENGTH = 9
+)
+
+// Config is the decoded content of the configuration registers (00H..08H)
+type Config struct {
+  Address           uint16  // ADDH, ADDL
+  NetID             byte
+  UARTRate          int     // bps
+  UARTParity        string  // 8N1, 8O1, 8E1
+  AirRate           int     // bps
+  SubPacket         int     // bytes
+  AmbientNoise      bool
+  Power             int     // dBm
+  Channel           byte
+  RSSI              bool
+  FixedTransmission bool
+  Repeater          bool
+  LBT               bool
+  WORTransmitter    bool
+  WORCycle          int     // ms
+  CryptKey          uint16  // CRYPT_H, CRYPT_L (write only)
+}
+
+var (
+  uartRates = map[byte]int{
+    UART_BAUD_1200: 1200,
+    UART_BAUD_2400: 2400,
+    UART_BAUD_4800: 4800,
+    UART_BAUD_9600: 9600,
+    UART_BAUD_19200: 19200,
+    UART_BAUD_38400: 38400,
+    UART_BAUD_57600: 57600,
+    UART_BAUD_115200: 115200,
+  }
+  uartParities = map[byte]string{
+    UART_8N1: "8N1",
+    UART_8O1: "8O1",
+    UART_8E1: "8E1",
+  }
+  airRates = map[byte]int{
+    AIR_BAUD_300: 300,
+    AIR_BAUD_1200: 1200,
+    AIR_BAUD_2400: 2400,
+    AIR_BAUD_4800: 4800,
+    AIR_BAUD_9600: 9600,
+    AIR_BAUD_19200: 19200,
+    AIR_BAUD_38400: 38400,
+    AIR_BAUD_62500: 62500,
+  }
+  subPackets = map[byte]int{
+    SUB_PACKET_BYTES_240: 240,
+    SUB_PACKET_BYTES_128: 128,
+    SUB_PACKET_BYTES_64: 64,
+    SUB_PACKET_BYTES_32: 32,
+  }
+  worCycles = map[byte]int{
+    WOR_CYCLE_MS_500: 500,
+    WOR_CYCLE_MS_1000: 1000,
+    WOR_CYCLE_MS_1500: 1500,
+    WOR_CYCLE_MS_2000: 2000,
+    WOR_CYCLE_MS_2500: 2500,
+    WOR_CYCLE_MS_3000: 3000,
+    WOR_CYCLE_MS_3500: 3500,
+    WOR_CYCLE_MS_4000: 4000,
+  }
+)
+
+// DefaultConfig returns the factory settings of the module
+func DefaultConfig() Config {
+  return Config{
+    UARTRate: 9600,
+    UARTParity: "8N1",
+    AirRate: 2400,
+    SubPacket: 240,
+    Power: 30,
+    Channel: 0x17,
+    WORCycle: 500,
+  }
+}
+
+func encode(name string, values map[byte]int, value int) (byte, error) {
+  for bits, item := range values {
+    if item == value {
+      return bits, nil
+    }
+  }
+  return 0, &LoRa.ConfigError{Field: name, Value: value}
+}
+
+func decode(name string, values map[byte]int, bits byte) (int, error) {
+  if value, ok := values[bits]; ok {
+    return value, nil
+  }
+  return 0, &LoRa.ConfigError{Field: name, Value: fmt.Sprintf("0x%02X", bits)}
+}
+
+func flag(value bool, enable byte) byte {
+  if value {
+    return enable
+  }
+  return 0
+}
+
+// MarshalRegisters encodes config of DEFAULT_MODEL into the 9-byte register
+// block 00H..08H
+func (c *Config) MarshalRegisters() ([]byte, error) {
+  return DEFAULT_MODEL.MarshalConfig(*c)
+}
+
+// UnmarshalRegisters decodes register block of DEFAULT_MODEL starting from 00H
+func (c *Config) UnmarshalRegisters(args []byte) error {
+  cfg, err := DEFAULT_MODEL.UnmarshalConfig(args)
+  if err == nil {
+    *c = cfg
+  }
+  return err
+}
+
+// MarshalConfig encodes config into the 9-byte register block 00H..08H
+func (m *Model) MarshalConfig(c Config) ([]byte, error) {
+  args := make([]byte, CONFIG_LENGTH)
+  args[REGISTER_ADDH[0]] = byte(c.Address >> 8)
+  args[REGISTER_ADDL[0]] = byte(c.Address)
+  args[REGISTER_NETID[0]] = c.NetID
+
+  bits, err := encode("UARTRate", uartRates, c.UARTRate)
+  if err != nil {
+    return nil, err
+  }
+  args[REGISTER_REG0[0]] |= bits
+  parity := false
+  for bits, item := range uartParities {
+    if item == c.UARTParity {
+      args[REGISTER_REG0[0]] |= bits
+      parity = true
+    }
+  }
+  if !parity {
+    return nil, &LoRa.ConfigError{Field: "UARTParity", Value: c.UARTParity}
+  }
+  if bits, err = encode("AirRate", airRates, c.AirRate); err != nil {
+    return nil, err
+  }
+  args[REGISTER_REG0[0]] |= bits
+
+  if bits, err = encode("SubPacket", subPackets, c.SubPacket); err != nil {
+    return nil, err
+  }
+  args[REGISTER_REG1[0]] |= bits
+  args[REGISTER_REG1[0]] |= flag(c.AmbientNoise, AMBIENT_NOISE_ENABLE)
+  if bits, err = encode("Power", m.powerTable(), c.Power); err != nil {
+    return nil, err
+  }
+  args[REGISTER_REG1[0]] |= bits
+
+  if int(c.Channel) >= m.plan.Channels {
+    return nil, &LoRa.ConfigError{Field: "Channel", Value: c.Channel}
+  }
+  args[REGISTER_REG2[0]] = c.Channel
+
+  args[REGISTER_REG3[0]] |= flag(c.RSSI, RSSI_ENABLE)
+  args[REGISTER_REG3[0]] |= flag(c.FixedTransmission, TRANSMISSION_MODE_FIXED)
+  args[REGISTER_REG3[0]] |= flag(c.Repeater, REPEATER_ENABLE)
+  args[REGISTER_REG3[0]] |= flag(c.LBT, LBT_ENABLE)
+  args[REGISTER_REG3[0]] |= flag(c.WORTransmitter, WOR_TRANSMITTER)
+  if bits, err = encode("WORCycle", worCycles, c.WORCycle); err != nil {
+    return nil, err
+  }
+  args[REGISTER_REG3[0]] |= bits
+
+  args[REGISTER_CRYPT_H[0]] = byte(c.CryptKey >> 8)
+  args[REGISTER_CRYPT_L[0]] = byte(c.CryptKey)
+  return args, nil
+}
+
+// UnmarshalConfig decodes register block starting from 00H; crypt
+// registers are optional since the module never returns them
+func (m *Model) UnmarshalConfig(args []byte) (Config, error) {
+  if len(args) < int(REGISTER_CRYPT_H[0]) {
+    return Config{}, &LoRa.ConfigError{Field: "register block length", Value: len(args)}
+  }
+  cfg := Config{}
+  cfg.Address = uint16(args[REGISTER_ADDH[0]]) << 8 | uint16(args[REGISTER_ADDL[0]])
+  cfg.NetID = args[REGISTER_NETID[0]]
+
+  var err error
+  reg := args[REGISTER_REG0[0]]
+  if cfg.UARTRate, err = decode("UARTRate", uartRates, reg & MASK_UART_BAUD); err != nil {
+    return cfg, err
+  }
+  parity, ok := uartParities[reg & MASK_UART_PARITY]
+  if !ok {
+    return cfg, &LoRa.ConfigError{Field: "UARTParity", Value: fmt.Sprintf("0x%02X", reg & MASK_UART_PARITY)}
+  }
+  cfg.UARTParity = parity
+  if cfg.AirRate, err = decode("AirRate", airRates, reg & MASK_AIR_BAUD); err != nil {
+    return cfg, err
+  }
+
+  reg = args[REGISTER_REG1[0]]
+  if cfg.SubPacket, err = decode("SubPacket", subPackets, reg & MASK_SUB_PACKET); err != nil {
+    return cfg, err
+  }
+  cfg.AmbientNoise = reg & MASK_AMBIENT_NOISE == AMBIENT_NOISE_ENABLE
+  if cfg.Power, err = decode("Power", m.powerTable(), reg & MASK_POWER); err != nil {
+    return cfg, err
+  }
+
+  cfg.Channel = args[REGISTER_REG2[0]]
+
+  reg = args[REGISTER_REG3[0]]
+  cfg.RSSI = reg & MASK_RSSI == RSSI_ENABLE
+  cfg.FixedTransmission = reg & MASK_TRANSMISSION_MODE == TRANSMISSION_MODE_FIXED
+  cfg.Repeater = reg & MASK_REPEATER == REPEATER_ENABLE
+  cfg.LBT = reg & MASK_LBT == LBT_ENABLE
+  cfg.WORTransmitter = reg & MASK_WOR_CONTROL == WOR_TRANSMITTER
+  if cfg.WORCycle, err = decode("WORCycle", worCycles, reg & MASK_WOR_CYCLE); err != nil {
+    return cfg, err
+  }
+
+  if len(args) >= CONFIG_LENGTH {
+    cfg.CryptKey = uint16(args[REGISTER_CRYPT_H[0]]) << 8 | uint16(args[REGISTER_CRYPT_L[0]])
+  }
+  return cfg, nil
+}
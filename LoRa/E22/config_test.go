@@ -0,0 +1,167 @@
+package E22
+
+import (
+  "bytes"
+  "e22config/LoRa"
+  "errors"
+  "testing"
+)
+
+func TestDefaultConfigRegisters(t *testing.T) {
+  cfg := DefaultConfig()
+  args, err := cfg.MarshalRegisters()
+  if err != nil {
+    t.Fatal(err)
+  }
+  expected := []byte{0x00, 0x00, 0x00, 0x62, 0x00, 0x17, 0x00, 0x00, 0x00}
+  if !bytes.Equal(args, expected) {
+    t.Fatalf("registers % X, expected % X", args, expected)
+  }
+}
+
+func TestConfigRoundTrip(t *testing.T) {
+  full := Config{
+    Address: 0x1234,
+    NetID: 0x56,
+    UARTRate: 115200,
+    UARTParity: "8E1",
+    AirRate: 62500,
+    SubPacket: 32,
+    AmbientNoise: true,
+    Power: 21,
+    Channel: 83,
+    RSSI: true,
+    FixedTransmission: true,
+    Repeater: true,
+    LBT: true,
+    WORTransmitter: true,
+    WORCycle: 4000,
+    CryptKey: 0xBEEF,
+  }
+  configs := []Config{DefaultConfig(), full}
+  for _, rate := range uartRates {
+    cfg := DefaultConfig()
+    cfg.UARTRate = rate
+    configs = append(configs, cfg)
+  }
+  for _, parity := range uartParities {
+    cfg := DefaultConfig()
+    cfg.UARTParity = parity
+    configs = append(configs, cfg)
+  }
+  for _, rate := range airRates {
+    cfg := DefaultConfig()
+    cfg.AirRate = rate
+    configs = append(configs, cfg)
+  }
+  for _, size := range subPackets {
+    cfg := DefaultConfig()
+    cfg.SubPacket = size
+    configs = append(configs, cfg)
+  }
+  for _, power := range DEFAULT_MODEL.Powers() {
+    cfg := DefaultConfig()
+    cfg.Power = power
+    configs = append(configs, cfg)
+  }
+  for _, cycle := range worCycles {
+    cfg := DefaultConfig()
+    cfg.WORCycle = cycle
+    configs = append(configs, cfg)
+  }
+
+  for _, cfg := range configs {
+    args, err := DEFAULT_MODEL.MarshalConfig(cfg)
+    if err != nil {
+      t.Fatalf("%+v: %v", cfg, err)
+    }
+    if len(args) != CONFIG_LENGTH {
+      t.Fatalf("%+v: %d registers", cfg, len(args))
+    }
+    decoded, err := DEFAULT_MODEL.UnmarshalConfig(args)
+    if err != nil {
+      t.Fatalf("%+v: %v", cfg, err)
+    }
+    if decoded != cfg {
+      t.Fatalf("decoded %+v, expected %+v", decoded, cfg)
+    }
+  }
+}
+
+func TestConfigModelPowers(t *testing.T) {
+  for _, model := range []*Model{E22_400T22D, E22_400T33D} {
+    for bits, power := range model.Powers() {
+      cfg := DefaultConfig()
+      cfg.Power = power
+      args, err := model.MarshalConfig(cfg)
+      if err != nil {
+        t.Fatalf("%s %d dBm: %v", model.Name(), power, err)
+      }
+      if args[REGISTER_REG1[0]] & MASK_POWER != byte(bits) {
+        t.Fatalf("%s %d dBm: REG1 0x%02X", model.Name(), power, args[REGISTER_REG1[0]])
+      }
+    }
+  }
+}
+
+func TestUnmarshalConfigWithoutCrypt(t *testing.T) {
+  cfg := DefaultConfig()
+  cfg.CryptKey = 0x1234
+  args, err := DEFAULT_MODEL.MarshalConfig(cfg)
+  if err != nil {
+    t.Fatal(err)
+  }
+  decoded, err := DEFAULT_MODEL.UnmarshalConfig(args[:REGISTER_CRYPT_H[0]])
+  if err != nil {
+    t.Fatal(err)
+  }
+  if decoded.CryptKey != 0 {
+    t.Fatalf("crypt key 0x%04X decoded from 7 registers", decoded.CryptKey)
+  }
+}
+
+func TestMarshalConfigErrors(t *testing.T) {
+  tests := []struct {
+    field string
+    change func(*Config)
+  }{
+    {"UARTRate", func(c *Config) { c.UARTRate = 14400 }},
+    {"UARTParity", func(c *Config) { c.UARTParity = "7N1" }},
+    {"AirRate", func(c *Config) { c.AirRate = 100 }},
+    {"SubPacket", func(c *Config) { c.SubPacket = 200 }},
+    {"Power", func(c *Config) { c.Power = 33 }},
+    {"Channel", func(c *Config) { c.Channel = 84 }},
+    {"WORCycle", func(c *Config) { c.WORCycle = 750 }},
+  }
+  for _, test := range tests {
+    cfg := DefaultConfig()
+    test.change(&cfg)
+    _, err := DEFAULT_MODEL.MarshalConfig(cfg)
+    var configErr *LoRa.ConfigError
+    if !errors.As(err, &configErr) {
+      t.Fatalf("%s: error %v, expected ConfigError", test.field, err)
+    }
+    if configErr.Field != test.field {
+      t.Fatalf("%s: error for %s", test.field, configErr.Field)
+    }
+  }
+}
+
+func TestUnmarshalConfigErrors(t *testing.T) {
+  valid, err := DEFAULT_MODEL.MarshalConfig(DefaultConfig())
+  if err != nil {
+    t.Fatal(err)
+  }
+  // UART parity bits 11 and the short block are the only invalid patterns
+  args := append([]byte{}, valid...)
+  args[REGISTER_REG0[0]] |= MASK_UART_PARITY
+  _, err = DEFAULT_MODEL.UnmarshalConfig(args)
+  var configErr *LoRa.ConfigError
+  if !errors.As(err, &configErr) || configErr.Field != "UARTParity" {
+    t.Fatalf("parity bits 11: error %v", err)
+  }
+  _, err = DEFAULT_MODEL.UnmarshalConfig(valid[:REGISTER_REG3[0]])
+  if !errors.As(err, &configErr) || configErr.Field != "register block length" {
+    t.Fatalf("short block: error %v", err)
+  }
+}
@@ -0,0 +1,40 @@
+package E22
+
+import (
+  "e22config/LoRa"
+)
+
+const (
+  TARGET_HEADER_LENGTH = 3    // ADDH, ADDL, channel
+
+  // BROADCAST_ADDRESS as target reaches every module on the channel; as own
+  // address it makes the module monitor all traffic on the channel
+  BROADCAST_ADDRESS uint16 = 0xFFFF
+  MONITOR_ADDRESS = BROADCAST_ADDRESS
+)
+
+// TargetHeader makes fixed transmission header of the target module
+func TargetHeader(address uint16, channel byte) []byte {
+  return []byte{byte(address >> 8), byte(address), channel}
+}
+
+// SendTo transmits payload to module with address on channel; local module
+// must be in fixed transmission mode and payload must fit into a sub-packet
+func (d *Device) SendTo(address uint16, channel byte, payload []byte) error {
+  cfg := d.config()
+  if !cfg.FixedTransmission {
+    return &LoRa.ConfigError{Field: "FixedTransmission", Value: cfg.FixedTransmission}
+  }
+  if int(channel) >= d.model().plan.Channels {
+    return &LoRa.ConfigError{Field: "Channel", Value: channel}
+  }
+  if len(payload) > cfg.SubPacket {
+    return &LoRa.PayloadError{Length: len(payload), Max: cfg.SubPacket}
+  }
+  return d.Send(TargetHeader(address, channel), payload)
+}
+
+// Broadcast transmits payload to every module on channel
+func (d *Device) Broadcast(channel byte, payload []byte) error {
+  return d.SendTo(BROADCAST_ADDRESS, channel, payload)
+}
@@ -0,0 +1,65 @@
+package E22
+
+import (
+  "bytes"
+  "e22config/LoRa"
+  "errors"
+  "testing"
+  "time"
+)
+
+// fixedDevice opens device in fixed transmission mode, written frames are
+// passed to sent
+func fixedDevice(t *testing.T, fixed bool, sent chan<- []byte) *Device {
+  d := fakeModule(t, func(command []byte) []byte {
+    sent <- command
+    return nil
+  })
+  cfg := DefaultConfig()
+  cfg.SubPacket = 32
+  cfg.FixedTransmission = fixed
+  d.Config = &cfg
+  return d
+}
+
+func TestSendToLimits(t *testing.T) {
+  sent := make(chan []byte, 1)
+  d := fixedDevice(t, true, sent)
+  err := d.SendTo(0x0102, 0x17, make([]byte, 33))
+  var payloadErr *LoRa.PayloadError
+  if !errors.As(err, &payloadErr) || payloadErr.Length != 33 || payloadErr.Max != 32 {
+    t.Fatalf("error %v, expected PayloadError", err)
+  }
+  var configErr *LoRa.ConfigError
+  // 400 MHz plan has 84 channels
+  if err := d.SendTo(0x0102, 84, []byte("hello")); !errors.As(err, &configErr) || configErr.Field != "Channel" {
+    t.Fatalf("error %v, expected Channel ConfigError", err)
+  }
+  // transparent mode would send the header as payload
+  d = fixedDevice(t, false, sent)
+  if err := d.SendTo(0x0102, 0x17, []byte("hello")); !errors.As(err, &configErr) || configErr.Field != "FixedTransmission" {
+    t.Fatalf("error %v, expected FixedTransmission ConfigError", err)
+  }
+  select {
+  case frame := <-sent:
+    t.Fatalf("rejected payload sent as % X", frame)
+  case <-time.After(50 * time.Millisecond):
+  }
+}
+
+func TestBroadcastHeader(t *testing.T) {
+  sent := make(chan []byte, 1)
+  d := fixedDevice(t, true, sent)
+  if err := d.Broadcast(0x10, []byte("all")); err != nil {
+    t.Fatal(err)
+  }
+  expected := append([]byte{0xFF, 0xFF, 0x10}, "all"...)
+  select {
+  case frame := <-sent:
+    if !bytes.Equal(frame, expected) {
+      t.Fatalf("sent % X, expected % X", frame, expected)
+    }
+  case <-time.After(time.Second):
+    t.Fatal("nothing sent")
+  }
+}
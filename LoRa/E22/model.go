@@ -0,0 +1,86 @@
+package E22
+
+import (
+  "e22config/LoRa"
+)
+
+// Model describes E22 variant: power levels and frequency plan
+type Model struct {
+  name          string
+  powers        []int     // dBm, indexed by REG1 power bits
+  plan          LoRa.FrequencyPlan
+}
+
+var (
+  PLAN_230 = LoRa.FrequencyPlan{Band: 230, Base: 220.125, Step: 0.25, Channels: 84, DefaultChannel: 0x28}
+  PLAN_400 = LoRa.FrequencyPlan{Band: 400, Base: 410.125, Step: 1, Channels: 84, DefaultChannel: 0x17}
+  PLAN_900 = LoRa.FrequencyPlan{Band: 900, Base: 850.125, Step: 1, Channels: 81, DefaultChannel: 0x12}
+
+  E22_230T30D = &Model{"E22-230T30D", []int{30, 27, 24, 21}, PLAN_230}
+  E22_400T22D = &Model{"E22-400T22D", []int{22, 17, 13, 10}, PLAN_400}
+  E22_400T30D = &Model{"E22-400T30D", []int{30, 27, 24, 21}, PLAN_400}
+  E22_400T33D = &Model{"E22-400T33D", []int{33, 30, 27, 24}, PLAN_400}
+  E22_900T22D = &Model{"E22-900T22D", []int{22, 17, 13, 10}, PLAN_900}
+  E22_900T30D = &Model{"E22-900T30D", []int{30, 27, 24, 21}, PLAN_900}
+
+  DEFAULT_MODEL = E22_400T30D
+)
+
+func init() {
+  LoRa.Register(E22_230T30D)
+  LoRa.Register(E22_400T22D)
+  LoRa.Register(E22_400T30D)
+  LoRa.Register(E22_400T33D)
+  LoRa.Register(E22_900T22D)
+  LoRa.Register(E22_900T30D)
+}
+
+// PlanFor returns frequency plan of E22 band (230, 400 or 900 MHz)
+func PlanFor(band int) (LoRa.FrequencyPlan, error) {
+  for _, plan := range []LoRa.FrequencyPlan{PLAN_230, PLAN_400, PLAN_900} {
+    if plan.Band == band {
+      return plan, nil
+    }
+  }
+  return LoRa.FrequencyPlan{}, &LoRa.ConfigError{Field: "band", Value: band}
+}
+
+func (m *Model) Name() string {
+  return m.name
+}
+
+func (m *Model) Family() string {
+  return "E22"
+}
+
+func (m *Model) Powers() []int {
+  return append([]int{}, m.powers...)
+}
+
+func (m *Model) AirRates() []int {
+  rates := make([]int, len(airRates))
+  for bits, rate := range airRates {
+    rates[bits] = rate
+  }
+  return rates
+}
+
+func (m *Model) WORCycles() []int {
+  cycles := make([]int, len(worCycles))
+  for bits, cycle := range worCycles {
+    cycles[bits] = cycle
+  }
+  return cycles
+}
+
+func (m *Model) Plan() LoRa.FrequencyPlan {
+  return m.plan
+}
+
+func (m *Model) powerTable() map[byte]int {
+  table := map[byte]int{}
+  for bits, power := range m.powers {
+    table[byte(bits)] = power
+  }
+  return table
+}
@@ -0,0 +1,86 @@
+package E22
+
+import (
+  "e22config/LoRa"
+  "errors"
+  "testing"
+)
+
+func TestPlanFrequency(t *testing.T) {
+  for _, test := range []struct {
+    band      int
+    channel   byte
+    frequency float64
+    ok        bool
+  }{
+    {230, 0, 220.125, true},
+    {230, 0x28, 230.125, true},
+    {230, 83, 240.875, true},
+    {230, 84, 0, false},
+    {400, 0, 410.125, true},
+    {400, 0x17, 433.125, true},
+    {400, 83, 493.125, true},
+    {400, 84, 0, false},
+    {900, 0, 850.125, true},
+    {900, 0x12, 868.125, true},
+    {900, 80, 930.125, true},
+    {900, 81, 0, false},
+  } {
+    plan, err := PlanFor(test.band)
+    if err != nil {
+      t.Fatal(err)
+    }
+    frequency, err := plan.Frequency(test.channel)
+    if (err == nil) != test.ok || frequency != test.frequency {
+      t.Fatalf("%d MHz channel %d: %g MHz, error %v", test.band, test.channel, frequency, err)
+    }
+  }
+}
+
+func TestPlanChannel(t *testing.T) {
+  for _, test := range []struct {
+    band      int
+    frequency float64
+    channel   byte
+    ok        bool
+  }{
+    {230, 220.125, 0, true},
+    {230, 230.125, 0x28, true},
+    {230, 230.2, 0x28, true},       // rounded to the nearest channel
+    {230, 240.875, 83, true},
+    {230, 220.05, 0, true},
+    {230, 219.9, 0, false},         // below channel 0
+    {230, 241, 0, false},           // beyond the last channel
+    {400, 433.1, 0x17, true},
+    {400, 433.125, 0x17, true},
+    {400, 433.7, 0x18, true},
+    {400, 493.125, 83, true},
+    {400, 409.5, 0, false},
+    {400, 494, 0, false},
+    {900, 868.1250, 0x12, true},
+    {900, 868, 0x12, true},
+    {900, 930.125, 80, true},
+    {900, 849.5, 0, false},
+    {900, 931, 0, false},
+  } {
+    plan, err := PlanFor(test.band)
+    if err != nil {
+      t.Fatal(err)
+    }
+    channel, err := plan.Channel(test.frequency)
+    if (err == nil) != test.ok || channel != test.channel {
+      t.Fatalf("%d MHz %g MHz: channel %d, error %v", test.band, test.frequency, channel, err)
+    }
+    var configErr *LoRa.ConfigError
+    if err != nil && !errors.As(err, &configErr) {
+      t.Fatalf("error %v, expected ConfigError", err)
+    }
+  }
+}
+
+func TestPlanForUnknownBand(t *testing.T) {
+  var configErr *LoRa.ConfigError
+  if _, err := PlanFor(433); !errors.As(err, &configErr) {
+    t.Fatalf("error %v, expected ConfigError", err)
+  }
+}
@@ -0,0 +1,77 @@
+package E22
+
+import (
+  "e22config/LoRa"
+  "time"
+)
+
+const (
+  MIN_PACKET_GAP = 20 * time.Millisecond  // covers USB-UART latency
+  PACKET_GAP_CHARS = 4                     // idle UART characters ending a packet
+)
+
+// Packet is data received from the air
+type Packet struct {
+  Data    []byte
+  RSSI    int     // dBm, valid if HasRSSI
+  HasRSSI bool
+}
+
+// SplitRSSI separates payload from the RSSI byte the module appends when
+// RSSI is enabled in cfg
+func SplitRSSI(data []byte, cfg Config) (Packet, error) {
+  if !cfg.RSSI {
+    return Packet{Data: data}, nil
+  }
+  if len(data) < 2 {
+    return Packet{}, &LoRa.ShortFrameError{Expected: 2, Received: len(data)}
+  }
+  last := len(data) - 1
+  return Packet{Data: data[:last], RSSI: RSSIdBm(data[last]), HasRSSI: true}, nil
+}
+
+// packetGap returns UART idle time separating packets at the configured rate
+func packetGap(cfg Config) time.Duration {
+  gap := time.Duration(0)
+  if cfg.UARTRate > 0 {
+    // 10 bits per 8N1 character
+    gap = time.Duration(PACKET_GAP_CHARS * 10) * time.Second / time.Duration(cfg.UARTRate)
+  }
+  if gap < MIN_PACKET_GAP {
+    gap = MIN_PACKET_GAP
+  }
+  return gap
+}
+
+// Receive waits for a packet from the air using active configuration of
+// the module; timeout <= 0 waits forever. Payloads longer than sub-packet
+// come as several packets
+func (d *Device) Receive(timeout time.Duration) (Packet, error) {
+  cfg := d.config()
+  if cfg.SubPacket <= 0 {
+    return Packet{}, &LoRa.ConfigError{Field: "SubPacket", Value: cfg.SubPacket}
+  }
+  max := cfg.SubPacket
+  if cfg.RSSI {
+    max++
+  }
+  data, err := d.Port.Receive(timeout, packetGap(cfg), max)
+  if err != nil {
+    return Packet{}, err
+  }
+  return SplitRSSI(data, cfg)
+}
+
+// Stream opens transparent link over the device; active configuration must
+// have fixed transmission and RSSI byte disabled, Receive reads packets
+// otherwise
+func (d *Device) Stream() (*LoRa.Stream, error) {
+  cfg := d.config()
+  if cfg.FixedTransmission {
+    return nil, &LoRa.ConfigError{Field: "FixedTransmission", Value: cfg.FixedTransmission}
+  }
+  if cfg.RSSI {
+    return nil, &LoRa.ConfigError{Field: "RSSI", Value: cfg.RSSI}
+  }
+  return LoRa.NewStream(&d.Port, cfg.SubPacket)
+}
@@ -0,0 +1,24 @@
+package E22
+
+import (
+  "e22config/LoRa"
+  "errors"
+  "testing"
+)
+
+func TestStreamConfig(t *testing.T) {
+  for _, field := range []string{"FixedTransmission", "RSSI"} {
+    d := fakeModule(t, func(command []byte) []byte {
+      return nil
+    })
+    cfg := DefaultConfig()
+    cfg.FixedTransmission = field == "FixedTransmission"
+    cfg.RSSI = field == "RSSI"
+    d.Config = &cfg
+    _, err := d.Stream()
+    var configErr *LoRa.ConfigError
+    if !errors.As(err, &configErr) || configErr.Field != field {
+      t.Fatalf("error %v, expected %s ConfigError", err, field)
+    }
+  }
+}
@@ -0,0 +1,201 @@
+package E22
+
+import (
+  "e22config/LoRa"
+  "errors"
+  "fmt"
+  "net"
+  "os"
+  "strconv"
+  "strings"
+  "sync"
+  "time"
+)
+
+const (
+  NETWORK = "e22"
+)
+
+// Addr is module address on a channel, it implements net.Addr
+type Addr struct {
+  Address uint16
+  Channel byte
+}
+
+func (a *Addr) Network() string {
+  return NETWORK
+}
+
+// String formats address as "address:channel"
+func (a *Addr) String() string {
+  if a == nil {
+    return "<nil>"
+  }
+  return fmt.Sprintf("%d:%d", a.Address, a.Channel)
+}
+
+// ResolveAddr parses "address:channel"; numbers may be given in hex (0x..)
+func ResolveAddr(s string) (*Addr, error) {
+  parts := strings.Split(s, ":")
+  if len(parts) != 2 {
+    return nil, &net.AddrError{Err: "address:channel expected", Addr: s}
+  }
+  address, err := strconv.ParseUint(parts[0], 0, 16)
+  if err != nil {
+    return nil, &net.AddrError{Err: "bad module address", Addr: s}
+  }
+  channel, err := strconv.ParseUint(parts[1], 0, 8)
+  if err != nil {
+    return nil, &net.AddrError{Err: "bad channel", Addr: s}
+  }
+  return &Addr{uint16(address), byte(channel)}, nil
+}
+
+// PacketConn implements net.PacketConn over module in fixed transmission
+// mode. Module doesn't report sender of received packets, so ReadFrom returns
+// broadcast address of the channel unless SourceHeader is set
+type PacketConn struct {
+  SourceHeader bool   // prepend own address and channel to every payload
+  device *Device
+  mutex sync.Mutex
+  readDeadline time.Time
+  writeDeadline time.Time
+  closed bool
+}
+
+var (
+  MissingAddress = errors.New("missing address")
+)
+
+// NewPacketConn wraps open device; its active configuration must have fixed
+// transmission enabled
+func NewPacketConn(d *Device) (*PacketConn, error) {
+  if !d.IsOpen {
+    return nil, LoRa.NotOpen
+  }
+  if cfg := d.config(); !cfg.FixedTransmission {
+    return nil, &LoRa.ConfigError{Field: "FixedTransmission", Value: cfg.FixedTransmission}
+  }
+  return &PacketConn{device: d}, nil
+}
+
+func (c *PacketConn) opError(op string, addr net.Addr, err error) error {
+  return &net.OpError{Op: op, Net: NETWORK, Source: c.LocalAddr(), Addr: addr, Err: err}
+}
+
+// ReadFrom receives a packet; RSSI byte is stripped if enabled
+func (c *PacketConn) ReadFrom(p []byte) (int, net.Addr, error) {
+  for {
+    c.mutex.Lock()
+    closed, deadline := c.closed, c.readDeadline
+    c.mutex.Unlock()
+    if closed {
+      return 0, nil, c.opError("read", nil, net.ErrClosed)
+    }
+    timeout := LoRa.DEADLINE_POLL
+    if !deadline.IsZero() {
+      left := time.Until(deadline)
+      if left <= 0 {
+        return 0, nil, c.opError("read", nil, os.ErrDeadlineExceeded)
+      }
+      if left < timeout {
+        timeout = left
+      }
+    }
+    packet, err := c.device.Receive(timeout)
+    if err == LoRa.NoData {
+      continue
+    }
+    if err != nil {
+      c.mutex.Lock()
+      if c.closed {
+        err = net.ErrClosed
+      }
+      c.mutex.Unlock()
+      return 0, nil, c.opError("read", nil, err)
+    }
+    cfg := c.device.config()
+    from := &Addr{BROADCAST_ADDRESS, cfg.Channel}
+    data := packet.Data
+    if c.SourceHeader {
+      if len(data) < TARGET_HEADER_LENGTH {
+        return 0, nil, c.opError("read", nil, &LoRa.ShortFrameError{Expected: TARGET_HEADER_LENGTH, Received: len(data)})
+      }
+      from = &Addr{uint16(data[0]) << 8 | uint16(data[1]), data[2]}
+      data = data[TARGET_HEADER_LENGTH:]
+    }
+    return copy(p, data), from, nil
+  }
+}
+
+// WriteTo sends p to *Addr; p must fit into a sub-packet
+func (c *PacketConn) WriteTo(p []byte, addr net.Addr) (int, error) {
+  to, ok := addr.(*Addr)
+  if addr == nil || (ok && to == nil) {
+    return 0, c.opError("write", nil, MissingAddress)
+  }
+  c.mutex.Lock()
+  closed, deadline := c.closed, c.writeDeadline
+  c.mutex.Unlock()
+  if closed {
+    return 0, c.opError("write", addr, net.ErrClosed)
+  }
+  if !deadline.IsZero() && !time.Now().Before(deadline) {
+    return 0, c.opError("write", addr, os.ErrDeadlineExceeded)
+  }
+  if !ok {
+    return 0, c.opError("write", addr, &net.AddrError{Err: "not an E22 address", Addr: addr.String()})
+  }
+  payload := p
+  if c.SourceHeader {
+    local := c.LocalAddr().(*Addr)
+    payload = append(TargetHeader(local.Address, local.Channel), p...)
+  }
+  if err := c.device.SendTo(to.Address, to.Channel, payload); err != nil {
+    return 0, c.opError("write", addr, err)
+  }
+  return len(p), nil
+}
+
+// Close stops pending reads and closes the device
+func (c *PacketConn) Close() error {
+  c.mutex.Lock()
+  if c.closed {
+    c.mutex.Unlock()
+    return c.opError("close", nil, net.ErrClosed)
+  }
+  c.closed = true
+  c.mutex.Unlock()
+  return c.device.Close()
+}
+
+// LocalAddr returns address and channel of the local module
+func (c *PacketConn) LocalAddr() net.Addr {
+  cfg := c.device.config()
+  return &Addr{cfg.Address, cfg.Channel}
+}
+
+func (c *PacketConn) SetDeadline(t time.Time) error {
+  c.mutex.Lock()
+  defer c.mutex.Unlock()
+  c.readDeadline = t
+  c.writeDeadline = t
+  return nil
+}
+
+func (c *PacketConn) SetReadDeadline(t time.Time) error {
+  c.mutex.Lock()
+  defer c.mutex.Unlock()
+  c.readDeadline = t
+  return nil
+}
+
+func (c *PacketConn) SetWriteDeadline(t time.Time) error {
+  c.mutex.Lock()
+  defer c.mutex.Unlock()
+  c.writeDeadline = t
+  return nil
+}
+
+var _ net.PacketConn = (*PacketConn)(nil)
+var _ net.Addr = (*Addr)(nil)
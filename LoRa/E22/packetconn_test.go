@@ -0,0 +1,86 @@
+package E22
+
+import (
+  "bytes"
+  "errors"
+  "net"
+  "testing"
+  "time"
+)
+
+func fixedConn(t *testing.T, received chan<- []byte) *PacketConn {
+  d := fakeModule(t, func(command []byte) []byte {
+    received <- command
+    return nil
+  })
+  cfg := DefaultConfig()
+  cfg.Address = 0x0102
+  cfg.FixedTransmission = true
+  d.Config = &cfg
+  c, err := NewPacketConn(d)
+  if err != nil {
+    t.Fatal(err)
+  }
+  return c
+}
+
+func TestPacketConnWriteTo(t *testing.T) {
+  received := make(chan []byte, 1)
+  c := fixedConn(t, received)
+  to, err := ResolveAddr("0x0304:5")
+  if err != nil {
+    t.Fatal(err)
+  }
+  if _, err := c.WriteTo([]byte("hello"), to); err != nil {
+    t.Fatal(err)
+  }
+  expected := append([]byte{0x03, 0x04, 0x05}, "hello"...)
+  select {
+  case frame := <-received:
+    if !bytes.Equal(frame, expected) {
+      t.Fatalf("sent % X, expected % X", frame, expected)
+    }
+  case <-time.After(time.Second):
+    t.Fatal("nothing sent")
+  }
+}
+
+func TestPacketConnMissingAddress(t *testing.T) {
+  c := fixedConn(t, make(chan []byte, 1))
+  var typed *Addr
+  for _, addr := range []net.Addr{nil, typed} {
+    _, err := c.WriteTo([]byte("hello"), addr)
+    var opErr *net.OpError
+    if !errors.As(err, &opErr) || !errors.Is(err, MissingAddress) {
+      t.Fatalf("%#v: error %v, expected missing address", addr, err)
+    }
+    _ = err.Error()
+  }
+}
+
+func TestPacketConnClosed(t *testing.T) {
+  c := fixedConn(t, make(chan []byte, 1))
+  read := make(chan error)
+  go func() {
+    _, _, err := c.ReadFrom(make([]byte, 16))
+    read <- err
+  }()
+  time.Sleep(10 * time.Millisecond)
+  if err := c.Close(); err != nil {
+    t.Fatal(err)
+  }
+  select {
+  case err := <-read:
+    if !errors.Is(err, net.ErrClosed) {
+      t.Fatalf("read error %v, expected net.ErrClosed", err)
+    }
+  case <-time.After(time.Second):
+    t.Fatal("ReadFrom isn't unblocked by Close")
+  }
+  if _, err := c.WriteTo([]byte("hello"), &Addr{1, 2}); !errors.Is(err, net.ErrClosed) {
+    t.Fatalf("write error %v, expected net.ErrClosed", err)
+  }
+  if err := c.Close(); !errors.Is(err, net.ErrClosed) {
+    t.Fatalf("close error %v, expected net.ErrClosed", err)
+  }
+}
@@ -0,0 +1,25 @@
+package E22
+
+import (
+  "e22config/LoRa"
+)
+
+const (
+  PRODUCT_INFO_LENGTH = 7
+)
+
+// ProductInfo is the content of product information registers (80H..86H).
+// EBYTE doesn't document the layout and there are no captured dumps of real
+// modules yet, so registers are kept raw and not decoded
+type ProductInfo struct {
+  Raw       []byte
+}
+
+// UnmarshalRegisters keeps product information registers in Raw
+func (p *ProductInfo) UnmarshalRegisters(args []byte) error {
+  if len(args) != PRODUCT_INFO_LENGTH {
+    return &LoRa.ConfigError{Field: "product information length", Value: len(args)}
+  }
+  *p = ProductInfo{Raw: append([]byte{}, args...)}
+  return nil
+}
@@ -0,0 +1,32 @@
+package E22
+
+import (
+  "bytes"
+  "e22config/LoRa"
+  "errors"
+  "testing"
+)
+
+func TestProductInfoKeepsRaw(t *testing.T) {
+  args := []byte{0x00, 0x22, 0x28, 0x1E, 0x01, 0x02, 0x00}
+  info := ProductInfo{}
+  if err := info.UnmarshalRegisters(args); err != nil {
+    t.Fatal(err)
+  }
+  if !bytes.Equal(info.Raw, args) {
+    t.Fatalf("raw % X, expected % X", info.Raw, args)
+  }
+  args[1] = 0x32
+  if info.Raw[1] != 0x22 {
+    t.Fatal("raw shares memory with registers")
+  }
+}
+
+func TestProductInfoLength(t *testing.T) {
+  info := ProductInfo{}
+  err := info.UnmarshalRegisters(make([]byte, PRODUCT_INFO_LENGTH - 1))
+  var configErr *LoRa.ConfigError
+  if !errors.As(err, &configErr) {
+    t.Fatalf("error %v, expected ConfigError", err)
+  }
+}
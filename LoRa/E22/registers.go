@@ -0,0 +1,80 @@
+package E22
+
+import (
+  "e22config/LoRa"
+  "fmt"
+)
+
+// RegisterInfo describes documented register
+type RegisterInfo struct {
+  Address   byte
+  Name      string
+  Readable  bool
+  Writable  bool
+}
+
+var (
+  REGISTER_MAP = []RegisterInfo{
+    {REGISTER_ADDH[0], "ADDH", true, true},
+    {REGISTER_ADDL[0], "ADDL", true, true},
+    {REGISTER_NETID[0], "NETID", true, true},
+    {REGISTER_REG0[0], "REG0", true, true},
+    {REGISTER_REG1[0], "REG1", true, true},
+    {REGISTER_REG2[0], "REG2", true, true},
+    {REGISTER_REG3[0], "REG3", true, true},
+    {REGISTER_CRYPT_H[0], "CRYPT_H", false, true},
+    {REGISTER_CRYPT_L[0], "CRYPT_L", false, true},
+    {REGISTER_PID0[0], "PID0", true, false},
+    {REGISTER_PID1[0], "PID1", true, false},
+    {REGISTER_PID2[0], "PID2", true, false},
+    {REGISTER_PID3[0], "PID3", true, false},
+    {REGISTER_PID4[0], "PID4", true, false},
+    {REGISTER_PID5[0], "PID5", true, false},
+    {REGISTER_PID6[0], "PID6", true, false},
+  }
+)
+
+// RegisterRange is a block of consecutive registers
+type RegisterRange struct {
+  Address   byte
+  Length    byte
+}
+
+// ReadableRanges returns readable registers of REGISTER_MAP grouped into
+// consecutive blocks, each is read by a single command (00H..06H, 80H..86H)
+func ReadableRanges() []RegisterRange {
+  ranges := []RegisterRange{}
+  for _, item := range REGISTER_MAP {
+    if !item.Readable {
+      continue
+    }
+    last := len(ranges) - 1
+    if last >= 0 && ranges[last].Address + ranges[last].Length == item.Address {
+      ranges[last].Length++
+      continue
+    }
+    ranges = append(ranges, RegisterRange{item.Address, 1})
+  }
+  return ranges
+}
+
+// RegisterName returns documented name of the register, empty if unknown
+func RegisterName(address byte) string {
+  for _, item := range REGISTER_MAP {
+    if item.Address == address {
+      return item.Name
+    }
+  }
+  return ""
+}
+
+// checkRange validates register range of a single command
+func checkRange(address byte, length int) error {
+  if length <= 0 || length > 0xFF {
+    return &LoRa.ConfigError{Field: "register count", Value: length}
+  }
+  if int(address) + length > 0x100 {
+    return &LoRa.ConfigError{Field: "register range", Value: fmt.Sprintf("%02XH+%d", address, length)}
+  }
+  return nil
+}
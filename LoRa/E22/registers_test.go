@@ -0,0 +1,18 @@
+package E22
+
+import (
+  "testing"
+)
+
+func TestReadableRanges(t *testing.T) {
+  ranges := ReadableRanges()
+  expected := []RegisterRange{{0x00, 7}, {0x80, 7}}
+  if len(ranges) != len(expected) {
+    t.Fatalf("ranges %+v, expected %+v", ranges, expected)
+  }
+  for i := range expected {
+    if ranges[i] != expected[i] {
+      t.Fatalf("ranges %+v, expected %+v", ranges, expected)
+    }
+  }
+}
@@ -0,0 +1,63 @@
+package E22_test
+
+import (
+  "e22config/LoRa/E22"
+  "e22config/LoRa/Transport"
+  "errors"
+  "testing"
+  "time"
+)
+
+func replay(t *testing.T, path string) (*E22.Device, *Transport.Replayer) {
+  r, err := Transport.ReplayFile(path)
+  if err != nil {
+    t.Fatal(err)
+  }
+  d := &E22.Device{Timeout: 200 * time.Millisecond}
+  if err := d.Open(r); err != nil {
+    t.Fatal(err)
+  }
+  t.Cleanup(func() {
+    d.Close()
+  })
+  return d, r
+}
+
+func TestReplayReadConfig(t *testing.T) {
+  d, r := replay(t, "testdata/read_config.txt")
+  cfg, err := d.ReadConfig()
+  if err != nil {
+    t.Fatal(err)
+  }
+  expected := E22.DefaultConfig()
+  expected.Address = 0x1234
+  expected.NetID = 2
+  expected.RSSI = true
+  expected.FixedTransmission = true
+  expected.WORCycle = 2000
+  if cfg != expected {
+    t.Fatalf("read %+v, expected %+v", cfg, expected)
+  }
+  info, err := d.ReadProductInfo()
+  if err != nil {
+    t.Fatal(err)
+  }
+  if len(info.Raw) != E22.PRODUCT_INFO_LENGTH || info.Raw[1] != 0x22 {
+    t.Fatalf("product information % X", info.Raw)
+  }
+  if !r.Done() {
+    t.Fatal("capture isn't played to the end")
+  }
+}
+
+func TestReplayMismatch(t *testing.T) {
+  d, _ := replay(t, "testdata/read_config.txt")
+  _, err := d.ReadProductInfo()
+  var mismatch *Transport.MismatchError
+  if !errors.As(err, &mismatch) {
+    t.Fatalf("error %v, expected MismatchError", err)
+  }
+  if mismatch.Entry != 0 || string(mismatch.Expected) != string(E22.COMMAND_GET_REGISTER[:]) + "\x00\x07" {
+    t.Fatalf("mismatch at entry %d, % X expected", mismatch.Entry, mismatch.Expected)
+  }
+}
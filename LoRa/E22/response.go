@@ -0,0 +1,48 @@
+package E22
+
+import (
+  "bytes"
+  "e22config/LoRa"
+  "errors"
+)
+
+const (
+  HEADER_LENGTH = 3 // command, start address, length
+)
+
+var (
+  WrongFormat = errors.New("Command rejected by device (wrong format)")
+)
+
+// IsWrongFormat reports if data starts with FF FF FF rejection
+func IsWrongFormat(data []byte) bool {
+  return bytes.HasPrefix(data, RESPONSE_WRONG_FORMAT[:])
+}
+
+// ParseResponse validates register response (C1 + address + length + data)
+// and returns its data part
+func ParseResponse(frame []byte, address, length byte) ([]byte, error) {
+  if IsWrongFormat(frame) {
+    return nil, WrongFormat
+  }
+  expected := HEADER_LENGTH + int(length)
+  if len(frame) < HEADER_LENGTH {
+    return nil, &LoRa.ShortFrameError{Expected: expected, Received: len(frame)}
+  }
+  if frame[0] != COMMAND_GET_REGISTER[0] {
+    return nil, &LoRa.FrameError{Field: "command", Expected: int(COMMAND_GET_REGISTER[0]), Received: int(frame[0])}
+  }
+  if frame[1] != address {
+    return nil, &LoRa.FrameError{Field: "start address", Expected: int(address), Received: int(frame[1])}
+  }
+  if frame[2] != length {
+    return nil, &LoRa.FrameError{Field: "length", Expected: int(length), Received: int(frame[2])}
+  }
+  if len(frame) < expected {
+    return nil, &LoRa.ShortFrameError{Expected: expected, Received: len(frame)}
+  }
+  if len(frame) > expected {
+    return nil, &LoRa.FrameError{Field: "frame length", Expected: expected, Received: len(frame)}
+  }
+  return frame[HEADER_LENGTH:], nil
+}
@@ -0,0 +1,73 @@
+package E22
+
+import (
+  "bytes"
+  "e22config/LoRa"
+  "errors"
+  "testing"
+)
+
+func TestParseResponse(t *testing.T) {
+  frame := []byte{0xC1, 0x00, 0x03, 0x12, 0x34, 0x56}
+  data, err := ParseResponse(frame, 0x00, 3)
+  if err != nil {
+    t.Fatal(err)
+  }
+  if !bytes.Equal(data, []byte{0x12, 0x34, 0x56}) {
+    t.Fatalf("data % X", data)
+  }
+}
+
+func TestParseResponseWrongFormat(t *testing.T) {
+  for _, frame := range [][]byte{
+    {0xFF, 0xFF, 0xFF},
+    {0xFF, 0xFF, 0xFF, 0x00},
+  } {
+    if _, err := ParseResponse(frame, 0x00, 3); err != WrongFormat {
+      t.Fatalf("% X: error %v, expected WrongFormat", frame, err)
+    }
+  }
+}
+
+func TestParseResponseShortFrame(t *testing.T) {
+  tests := []struct {
+    frame []byte
+    received int
+  }{
+    {[]byte{}, 0},
+    {[]byte{0xC1, 0x00}, 2},          // short header
+    {[]byte{0xC1, 0x00, 0x03, 0x12}, 4}, // truncated data
+  }
+  for _, test := range tests {
+    _, err := ParseResponse(test.frame, 0x00, 3)
+    var short *LoRa.ShortFrameError
+    if !errors.As(err, &short) {
+      t.Fatalf("% X: error %v, expected ShortFrameError", test.frame, err)
+    }
+    if short.Expected != 6 || short.Received != test.received {
+      t.Fatalf("% X: %d of %d bytes", test.frame, short.Received, short.Expected)
+    }
+  }
+}
+
+func TestParseResponseGarbled(t *testing.T) {
+  tests := []struct {
+    frame []byte
+    field string
+  }{
+    {[]byte{0xC0, 0x00, 0x03, 0x12, 0x34, 0x56}, "command"},
+    {[]byte{0xC1, 0x01, 0x03, 0x12, 0x34, 0x56}, "start address"},
+    {[]byte{0xC1, 0x00, 0x02, 0x12, 0x34}, "length"},
+    {[]byte{0xC1, 0x00, 0x03, 0x12, 0x34, 0x56, 0x78}, "frame length"},
+  }
+  for _, test := range tests {
+    _, err := ParseResponse(test.frame, 0x00, 3)
+    var garbled *LoRa.FrameError
+    if !errors.As(err, &garbled) {
+      t.Fatalf("% X: error %v, expected FrameError", test.frame, err)
+    }
+    if garbled.Field != test.field {
+      t.Fatalf("% X: error for %s, expected %s", test.frame, garbled.Field, test.field)
+    }
+  }
+}
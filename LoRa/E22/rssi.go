@@ -0,0 +1,59 @@
+package E22
+
+import (
+  "context"
+  "e22config/LoRa"
+)
+
+const (
+  RSSI_LENGTH = 2 // noise, last packet RSSI
+)
+
+// RSSI holds signal levels reported by the module
+type RSSI struct {
+  Noise int   // current channel noise, dBm, 0 if no reading
+  Last  int   // last received packet, dBm, 0 if no reading
+}
+
+// RSSIdBm converts RSSI register value to dBm; value 0 means no reading
+// (e.g. nothing received yet) and 0 is returned for it
+func RSSIdBm(value byte) int {
+  if value == 0 {
+    return 0
+  }
+  return -(256 - int(value))
+}
+
+// ReadRSSI queries noise and last packet RSSI (C0 C1 C2 C3) of the local
+// module; it is answered in transmission modes only and needs AmbientNoise
+// enabled in REG1
+func (d *Device) ReadRSSI() (RSSI, error) {
+  rssi := RSSI{}
+  timeout := d.Timeout
+  if timeout <= 0 {
+    timeout = DEFAULT_TIMEOUT
+  }
+  complete := func(response []byte) bool {
+    return len(response) >= HEADER_LENGTH + RSSI_LENGTH || IsWrongFormat(response)
+  }
+  var args []byte
+  err := LoRa.WithMode(d.Pins, LoRa.MODE_NORMAL, func() error {
+    ctx := context.Background()
+    return d.Retry.Do(ctx, "RSSI command", func() error {
+      attempt, cancel := context.WithTimeout(ctx, timeout)
+      defer cancel()
+      response, err := d.Command(attempt, complete, COMMAND_READ_RSSI[:], []byte{RSSI_NOISE[0], RSSI_LENGTH})
+      if err != nil {
+        return err
+      }
+      args, err = ParseResponse(response, RSSI_NOISE[0], RSSI_LENGTH)
+      return err
+    })
+  })
+  if err != nil {
+    return rssi, err
+  }
+  rssi.Noise = RSSIdBm(args[RSSI_NOISE[0]])
+  rssi.Last = RSSIdBm(args[RSSI_LAST[0]])
+  return rssi, nil
+}
@@ -0,0 +1,22 @@
+package E22
+
+import (
+  "testing"
+)
+
+func TestRSSIdBm(t *testing.T) {
+  for _, test := range []struct {
+    value byte
+    dBm   int
+  }{
+    {0x00, 0},      // no reading
+    {0x01, -255},
+    {0x80, -128},
+    {0xA0, -96},
+    {0xFF, -1},
+  } {
+    if dBm := RSSIdBm(test.value); dBm != test.dBm {
+      t.Fatalf("%02X: %d dBm, expected %d", test.value, dBm, test.dBm)
+    }
+  }
+}
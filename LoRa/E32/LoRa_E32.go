@@ -0,0 +1,214 @@
+package E32
+
+import (
+  "bytes"
+  "context"
+  "e22config/LoRa"
+  "time"
+)
+
+var (
+  FACTORY_DEFAULTS                    = [...]byte{0xC0, 0x00, 0x00, 0x1A, 0x17, 0x44}
+
+  COMMAND_SAVE_PARAMETERS             = [...]byte{0xC0}
+  COMMAND_READ_PARAMETERS             = [...]byte{0xC1, 0xC1, 0xC1}
+  COMMAND_TEMPORARY_PARAMETERS        = [...]byte{0xC2}
+  COMMAND_READ_VERSION                = [...]byte{0xC3, 0xC3, 0xC3}
+  COMMAND_RESET                       = [...]byte{0xC4, 0xC4, 0xC4}
+
+  PARAMETER_HEAD                      = [...]byte{0x00}
+  PARAMETER_ADDH                      = [...]byte{0x01}
+  PARAMETER_ADDL                      = [...]byte{0x02}
+  PARAMETER_SPED                      = [...]byte{0x03}
+  PARAMETER_CHAN                      = [...]byte{0x04}
+  PARAMETER_OPTION                    = [...]byte{0x05}
+
+  UART_8N1 byte                       = 0x00
+  UART_8O1 byte                       = 0x40
+  UART_8E1 byte                       = 0x80
+
+  UART_BAUD_1200 byte                 = 0x00
+  UART_BAUD_2400 byte                 = 0x08
+  UART_BAUD_4800 byte                 = 0x10
+  UART_BAUD_9600 byte                 = 0x18
+  UART_BAUD_19200 byte                = 0x20
+  UART_BAUD_38400 byte                = 0x28
+  UART_BAUD_57600 byte                = 0x30
+  UART_BAUD_115200 byte               = 0x38
+
+  TRANSMISSION_MODE_FIXED byte        = 0x80
+  TRANSMISSION_MODE_TRANSPARENT byte  = 0x00
+
+  IO_PUSH_PULL byte                   = 0x40
+  IO_OPEN_COLLECTOR byte              = 0x00
+
+  WAKE_UP_MS_250 byte                 = 0x00
+  WAKE_UP_MS_500 byte                 = 0x08
+  WAKE_UP_MS_750 byte                 = 0x10
+  WAKE_UP_MS_1000 byte                = 0x18
+  WAKE_UP_MS_1250 byte                = 0x20
+  WAKE_UP_MS_1500 byte                = 0x28
+  WAKE_UP_MS_1750 byte                = 0x30
+  WAKE_UP_MS_2000 byte                = 0x38
+
+  FEC_ENABLE byte                     = 0x04
+  FEC_DISABLE byte                    = 0x00
+
+  MASK_UART_PARITY byte               = 0xC0
+  MASK_UART_BAUD byte                 = 0x38
+  MASK_AIR_BAUD byte                  = 0x07
+  MASK_CHANNEL byte                   = 0x1F
+  MASK_TRANSMISSION_MODE byte         = 0x80
+  MASK_IO_DRIVE byte                  = 0x40
+  MASK_WAKE_UP byte                   = 0x38
+  MASK_FEC byte                       = 0x04
+  MASK_POWER byte                     = 0x03
+)
+
+const (
+  PARAMETERS_LENGTH = 6   // HEAD, ADDH, ADDL, SPED, CHAN, OPTION
+  VERSION_LENGTH = 4      // HEAD, frequency, version, features
+  SUB_PACKET_LENGTH = 58  // bytes sent over the air at once
+  DEFAULT_TIMEOUT = time.Second
+)
+
+// Device drives E32/E31 module through any Transport
+type Device struct {
+  LoRa.Port
+  Timeout time.Duration   // response timeout, DEFAULT_TIMEOUT if zero
+  Model *Model            // module variant, DEFAULT_MODEL if nil
+  Pins LoRa.ModeController  // M0/M1 control, modes are set by jumpers if nil
+  Retry LoRa.RetryPolicy  // repeats failed commands, single attempt if zero
+}
+
+func (d *Device) model() *Model {
+  if d.Model != nil {
+    return d.Model
+  }
+  return DEFAULT_MODEL
+}
+
+func (d *Device) timeout() time.Duration {
+  if d.Timeout > 0 {
+    return d.Timeout
+  }
+  return DEFAULT_TIMEOUT
+}
+
+// command sends data and waits for a response of given length starting with
+// one of heads
+func (d *Device) command(ctx context.Context, heads []byte, length int, data ...[]byte) ([]byte, error) {
+  complete := func(response []byte) bool {
+    return len(response) >= length
+  }
+  // E31/E32 are configured in sleep mode
+  var frame []byte
+  err := LoRa.WithMode(d.Pins, LoRa.MODE_SLEEP, func() error {
+    return d.Retry.Do(ctx, "Command", func() error {
+      attempt, cancel := context.WithTimeout(ctx, d.timeout())
+      defer cancel()
+      response, err := d.Command(attempt, complete, data...)
+      if err != nil {
+        return err
+      }
+      if len(response) < length {
+        return &LoRa.ShortFrameError{Expected: length, Received: len(response)}
+      }
+      if len(response) > length {
+        return &LoRa.FrameError{Field: "frame length", Expected: length, Received: len(response)}
+      }
+      if bytes.IndexByte(heads, response[0]) < 0 {
+        return &LoRa.FrameError{Field: "head", Expected: int(heads[0]), Received: int(response[0])}
+      }
+      frame = response
+      return nil
+    })
+  })
+  return frame, err
+}
+
+// ReadParameters reads raw parameter frame (C0 + 5 bytes)
+func (d *Device) ReadParameters() ([]byte, error) {
+  return d.command(context.Background(), COMMAND_SAVE_PARAMETERS[:], PARAMETERS_LENGTH, COMMAND_READ_PARAMETERS[:])
+}
+
+// WriteParameters writes 5 parameter bytes (ADDH..OPTION) saved in flash
+func (d *Device) WriteParameters(args []byte) error {
+  return d.setParameters(COMMAND_SAVE_PARAMETERS[:], args)
+}
+
+// ApplyParameters writes 5 parameter bytes (ADDH..OPTION); values are not
+// saved and module reverts them on power cycle
+func (d *Device) ApplyParameters(args []byte) error {
+  return d.setParameters(COMMAND_TEMPORARY_PARAMETERS[:], args)
+}
+
+func (d *Device) setParameters(head []byte, args []byte) error {
+  if len(args) != PARAMETERS_LENGTH - 1 {
+    return &LoRa.ConfigError{Field: "parameters length", Value: len(args)}
+  }
+  // module echoes written parameters; datasheets show C0 as the head, but
+  // some firmware echoes the command head (C2 for temporary write)
+  heads := []byte{head[0], COMMAND_SAVE_PARAMETERS[0]}
+  _, err := d.command(context.Background(), heads, PARAMETERS_LENGTH, head, args)
+  return err
+}
+
+// ReadConfig reads and decodes module parameters
+func (d *Device) ReadConfig() (Config, error) {
+  frame, err := d.ReadParameters()
+  if err != nil {
+    return Config{}, err
+  }
+  return d.model().UnmarshalConfig(frame[PARAMETER_ADDH[0]:])
+}
+
+// WriteConfig encodes and saves module parameters
+func (d *Device) WriteConfig(cfg Config) error {
+  args, err := d.model().MarshalConfig(cfg)
+  if err != nil {
+    return err
+  }
+  return d.WriteParameters(args)
+}
+
+// ApplyConfig encodes and applies module parameters without saving
+func (d *Device) ApplyConfig(cfg Config) error {
+  args, err := d.model().MarshalConfig(cfg)
+  if err != nil {
+    return err
+  }
+  return d.ApplyParameters(args)
+}
+
+// ReadVersion reads and decodes module version (C3 C3 C3)
+func (d *Device) ReadVersion() (Version, error) {
+  version := Version{}
+  frame, err := d.command(context.Background(), COMMAND_READ_VERSION[:1], VERSION_LENGTH, COMMAND_READ_VERSION[:])
+  if err != nil {
+    return version, err
+  }
+  err = version.UnmarshalBinary(frame)
+  return version, err
+}
+
+// Reset restarts the module (C4 C4 C4); module sends no response, but
+// holds AUX low during self-check which is awaited if AUX is monitored
+func (d *Device) Reset() error {
+  return LoRa.WithMode(d.Pins, LoRa.MODE_SLEEP, func() error {
+    if err := d.Send(COMMAND_RESET[:]); err != nil {
+      return err
+    }
+    if d.Aux == nil {
+      return nil
+    }
+    time.Sleep(LoRa.AUX_POLL_INTERVAL)
+    return d.WaitReady(d.timeout())
+  })
+}
+
+// Stream opens transparent link over the device; module must be configured
+// with fixed transmission disabled
+func (d *Device) Stream() (*LoRa.Stream, error) {
+  return LoRa.NewStream(&d.Port, SUB_PACKET_LENGTH)
+}
@@ -0,0 +1,75 @@
+package E32
+
+import (
+  "e22config/LoRa"
+  "errors"
+  "net"
+  "testing"
+  "time"
+)
+
+// echoModule answers every command with its parameters under reply head
+func echoModule(t *testing.T, reply byte) *Device {
+  host, module := net.Pipe()
+  go func() {
+    buffer := make([]byte, 64)
+    for {
+      n, err := module.Read(buffer)
+      if err != nil {
+        return
+      }
+      response := append([]byte{reply}, buffer[1:n]...)
+      if _, err := module.Write(response); err != nil {
+        return
+      }
+    }
+  }()
+  d := &Device{Timeout: 200 * time.Millisecond}
+  if err := d.Open(host); err != nil {
+    t.Fatal(err)
+  }
+  t.Cleanup(func() {
+    d.Close()
+    module.Close()
+  })
+  return d
+}
+
+func TestApplyConfigAcceptsEchoedHead(t *testing.T) {
+  for _, reply := range []byte{COMMAND_SAVE_PARAMETERS[0], COMMAND_TEMPORARY_PARAMETERS[0]} {
+    d := echoModule(t, reply)
+    if err := d.ApplyConfig(DefaultConfig()); err != nil {
+      t.Fatalf("reply 0x%02X: %v", reply, err)
+    }
+  }
+}
+
+func TestWriteConfigRejectsTemporaryHead(t *testing.T) {
+  d := echoModule(t, COMMAND_TEMPORARY_PARAMETERS[0])
+  err := d.WriteConfig(DefaultConfig())
+  var garbled *LoRa.FrameError
+  if !errors.As(err, &garbled) || garbled.Field != "head" {
+    t.Fatalf("error %v, expected head FrameError", err)
+  }
+}
+
+func TestVersionModels(t *testing.T) {
+  // all 433 MHz variants answer C3 32 xx xx
+  version := Version{0x32, 0x10, 0x14}
+  list := version.Models()
+  for _, expected := range []*Model{E32_433T30D, E32_433T33D, E31_433T33D} {
+    found := false
+    for _, m := range list {
+      found = found || m == expected
+    }
+    if !found {
+      t.Fatalf("%s isn't among detected models", expected.Name())
+    }
+  }
+  if len(list) != 3 {
+    t.Fatalf("%d models detected, expected 3", len(list))
+  }
+  if list := (Version{0x45, 0x10, 0x14}).Models(); len(list) != 0 {
+    t.Fatalf("%d models detected for 868 MHz", len(list))
+  }
+}
@@ -0,0 +1,173 @@
+package E32
+
+import (
+  "e22config/LoRa"
+  "fmt"
+)
+
+// Config is the decoded content of the parameter frame (ADDH..OPTION)
+type Config struct {
+  Address           uint16  // ADDH, ADDL
+  UARTRate          int     // bps
+  UARTParity        string  // 8N1, 8O1, 8E1
+  AirRate           int     // bps
+  Channel           byte
+  FixedTransmission bool
+  IOPushPull        bool    // AUX, TXD drive mode, open collector if false
+  WakeUpTime        int     // ms
+  FEC               bool
+  Power             int     // dBm
+}
+
+// Version is the decoded response to C3 C3 C3
+type Version struct {
+  Frequency byte
+  Version   byte
+  Features  byte
+}
+
+var (
+  uartRates = []int{1200, 2400, 4800, 9600, 19200, 38400, 57600, 115200}
+  uartParities = map[byte]string{
+    UART_8N1: "8N1",
+    UART_8O1: "8O1",
+    UART_8E1: "8E1",
+    0xC0: "8N1",  // 11 is equal to 00
+  }
+  wakeUpTimes = []int{250, 500, 750, 1000, 1250, 1500, 1750, 2000}
+  bands = map[byte]int{
+    0x32: 433,
+    0x38: 470,
+    0x45: 868,
+    0x44: 915,
+    0x46: 170,
+  }
+)
+
+// DefaultConfig returns the factory settings of the module
+func DefaultConfig() Config {
+  cfg, _ := DEFAULT_MODEL.UnmarshalConfig(FACTORY_DEFAULTS[PARAMETER_ADDH[0]:])
+  return cfg
+}
+
+// index returns position of value in table, bits are shifted by shift
+func index(name string, table []int, value int, shift uint) (byte, error) {
+  for i, item := range table {
+    if item == value {
+      return byte(i) << shift, nil
+    }
+  }
+  return 0, &LoRa.ConfigError{Field: name, Value: value}
+}
+
+func flag(value bool, enable byte) byte {
+  if value {
+    return enable
+  }
+  return 0
+}
+
+// MarshalConfig encodes config into 5 parameter bytes (ADDH..OPTION)
+func (m *Model) MarshalConfig(c Config) ([]byte, error) {
+  args := make([]byte, PARAMETERS_LENGTH)
+  args[PARAMETER_ADDH[0]] = byte(c.Address >> 8)
+  args[PARAMETER_ADDL[0]] = byte(c.Address)
+
+  parity := false
+  for bits, item := range uartParities {
+    if item == c.UARTParity && bits != 0xC0 {
+      args[PARAMETER_SPED[0]] |= bits
+      parity = true
+    }
+  }
+  if !parity {
+    return nil, &LoRa.ConfigError{Field: "UARTParity", Value: c.UARTParity}
+  }
+  bits, err := index("UARTRate", uartRates, c.UARTRate, 3)
+  if err != nil {
+    return nil, err
+  }
+  args[PARAMETER_SPED[0]] |= bits
+  if bits, err = index("AirRate", m.airRates, c.AirRate, 0); err != nil {
+    return nil, err
+  }
+  args[PARAMETER_SPED[0]] |= bits
+
+  if int(c.Channel) >= m.plan.Channels {
+    return nil, &LoRa.ConfigError{Field: "Channel", Value: c.Channel}
+  }
+  args[PARAMETER_CHAN[0]] = c.Channel
+
+  args[PARAMETER_OPTION[0]] |= flag(c.FixedTransmission, TRANSMISSION_MODE_FIXED)
+  args[PARAMETER_OPTION[0]] |= flag(c.IOPushPull, IO_PUSH_PULL)
+  if bits, err = index("WakeUpTime", wakeUpTimes, c.WakeUpTime, 3); err != nil {
+    return nil, err
+  }
+  args[PARAMETER_OPTION[0]] |= bits
+  args[PARAMETER_OPTION[0]] |= flag(c.FEC, FEC_ENABLE)
+  if bits, err = index("Power", m.powers, c.Power, 0); err != nil {
+    return nil, err
+  }
+  args[PARAMETER_OPTION[0]] |= bits
+  return args[PARAMETER_ADDH[0]:], nil
+}
+
+// UnmarshalConfig decodes 5 parameter bytes (ADDH..OPTION)
+func (m *Model) UnmarshalConfig(args []byte) (Config, error) {
+  cfg := Config{}
+  if len(args) != PARAMETERS_LENGTH - 1 {
+    return cfg, &LoRa.ConfigError{Field: "parameters length", Value: len(args)}
+  }
+  frame := append([]byte{COMMAND_SAVE_PARAMETERS[0]}, args...)
+  cfg.Address = uint16(frame[PARAMETER_ADDH[0]]) << 8 | uint16(frame[PARAMETER_ADDL[0]])
+
+  reg := frame[PARAMETER_SPED[0]]
+  cfg.UARTParity = uartParities[reg & MASK_UART_PARITY]
+  cfg.UARTRate = uartRates[(reg & MASK_UART_BAUD) >> 3]
+  air := int(reg & MASK_AIR_BAUD)
+  if air >= len(m.airRates) {
+    // E32 repeats the highest rate for unused patterns
+    air = len(m.airRates) - 1
+  }
+  cfg.AirRate = m.airRates[air]
+
+  cfg.Channel = frame[PARAMETER_CHAN[0]] & MASK_CHANNEL
+  if int(cfg.Channel) >= m.plan.Channels {
+    return cfg, &LoRa.ConfigError{Field: "Channel", Value: fmt.Sprintf("0x%02X", cfg.Channel)}
+  }
+
+  reg = frame[PARAMETER_OPTION[0]]
+  cfg.FixedTransmission = reg & MASK_TRANSMISSION_MODE == TRANSMISSION_MODE_FIXED
+  cfg.IOPushPull = reg & MASK_IO_DRIVE == IO_PUSH_PULL
+  cfg.WakeUpTime = wakeUpTimes[(reg & MASK_WAKE_UP) >> 3]
+  cfg.FEC = reg & MASK_FEC == FEC_ENABLE
+  cfg.Power = m.powers[reg & MASK_POWER]
+  return cfg, nil
+}
+
+// UnmarshalBinary decodes version frame (C3 + frequency + version + features)
+func (v *Version) UnmarshalBinary(frame []byte) error {
+  if len(frame) != VERSION_LENGTH || frame[0] != COMMAND_READ_VERSION[0] {
+    return &LoRa.ConfigError{Field: "version frame", Value: fmt.Sprintf("% X", frame)}
+  }
+  *v = Version{frame[1], frame[2], frame[3]}
+  return nil
+}
+
+// Band returns frequency band in MHz, 0 if unknown
+func (v Version) Band() int {
+  return bands[v.Frequency]
+}
+
+// Models returns models of the version band. Features byte isn't documented,
+// so the version tells neither E31 from E32 nor power class and several
+// models may be returned
+func (v Version) Models() []*Model {
+  list := []*Model{}
+  for _, m := range models {
+    if m.plan.Band == v.Band() {
+      list = append(list, m)
+    }
+  }
+  return list
+}
@@ -0,0 +1,59 @@
+package E32
+
+import (
+  "e22config/LoRa"
+)
+
+// Model describes E32/E31 variant: power levels, air rates and frequency plan
+type Model struct {
+  name          string
+  family        string
+  powers        []int     // dBm, indexed by OPTION power bits
+  airRates      []int     // bps, indexed by SPED air rate bits
+  plan          LoRa.FrequencyPlan
+}
+
+var (
+  e32AirRates = []int{300, 1200, 2400, 4800, 9600, 19200}
+  e31AirRates = []int{1200, 2400, 4800, 9600, 19200, 50000, 100000, 200000}
+
+  PLAN_433 = LoRa.FrequencyPlan{Band: 433, Base: 410, Step: 1, Channels: 32, DefaultChannel: 0x17}
+
+  E32_433T30D = &Model{"E32-433T30D", "E32", []int{30, 27, 24, 21}, e32AirRates, PLAN_433}
+  E32_433T33D = &Model{"E32-433T33D", "E32", []int{33, 30, 27, 24}, e32AirRates, PLAN_433}
+  E31_433T33D = &Model{"E31-433T33D", "E31", []int{33, 30, 27, 24}, e31AirRates, PLAN_433}
+
+  DEFAULT_MODEL = E32_433T30D
+
+  models = []*Model{E32_433T30D, E32_433T33D, E31_433T33D}
+)
+
+func init() {
+  for _, m := range models {
+    LoRa.Register(m)
+  }
+}
+
+func (m *Model) Name() string {
+  return m.name
+}
+
+func (m *Model) Family() string {
+  return m.family
+}
+
+func (m *Model) Powers() []int {
+  return append([]int{}, m.powers...)
+}
+
+func (m *Model) AirRates() []int {
+  return append([]int{}, m.airRates...)
+}
+
+func (m *Model) WORCycles() []int {
+  return append([]int{}, wakeUpTimes...)
+}
+
+func (m *Model) Plan() LoRa.FrequencyPlan {
+  return m.plan
+}
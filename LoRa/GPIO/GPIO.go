@@ -0,0 +1,16 @@
+package GPIO
+
+import (
+  "errors"
+)
+
+var (
+  NotSupported = errors.New("GPIO character device is not supported on this platform")
+)
+
+// Lines is a set of GPIO lines requested together
+type Lines interface {
+  Get() ([]bool, error)
+  Set(values []bool) error
+  Close() error
+}
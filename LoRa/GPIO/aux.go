@@ -0,0 +1,48 @@
+package GPIO
+
+// AuxPin reads module AUX pin wired to GPIO line
+type AuxPin struct {
+  lines Lines
+}
+
+// NewAuxPin requests AUX line of the chip as input
+func NewAuxPin(chip string, line uint32) (*AuxPin, error) {
+  lines, err := Request(chip, []uint32{line}, nil, "e22config AUX")
+  if err != nil {
+    return nil, err
+  }
+  return &AuxPin{lines}, nil
+}
+
+// Ready reports if AUX is high (module is idle)
+func (p *AuxPin) Ready() (bool, error) {
+  values, err := p.lines.Get()
+  if err != nil {
+    return false, err
+  }
+  return values[0], nil
+}
+
+// Close releases GPIO line
+func (p *AuxPin) Close() error {
+  return p.lines.Close()
+}
+
+// FakeAux reports module busy for a number of polls
+type FakeAux struct {
+  BusyPolls int     // polls left to report AUX low
+  Polls     int     // total number of polls
+  Err       error   // returned by Ready if set
+}
+
+func (p *FakeAux) Ready() (bool, error) {
+  p.Polls++
+  if p.Err != nil {
+    return false, p.Err
+  }
+  if p.BusyPolls > 0 {
+    p.BusyPolls--
+    return false, nil
+  }
+  return true, nil
+}
@@ -0,0 +1,101 @@
+// +build linux
+
+package GPIO
+
+import (
+  "fmt"
+  "os"
+  "syscall"
+  "unsafe"
+)
+
+// GPIO character device ABI v1 (linux/gpio.h)
+const (
+  GPIOHANDLES_MAX = 64
+  GPIOHANDLE_REQUEST_INPUT = 1 << 0
+  GPIOHANDLE_REQUEST_OUTPUT = 1 << 1
+  GPIO_GET_LINEHANDLE_IOCTL = 0xC16CB403
+  GPIOHANDLE_GET_LINE_VALUES_IOCTL = 0xC040B408
+  GPIOHANDLE_SET_LINE_VALUES_IOCTL = 0xC040B409
+)
+
+type handleRequest struct {
+  lineOffsets   [GPIOHANDLES_MAX]uint32
+  flags         uint32
+  defaultValues [GPIOHANDLES_MAX]uint8
+  consumerLabel [32]byte
+  lines         uint32
+  fd            int32
+}
+
+type handleData struct {
+  values [GPIOHANDLES_MAX]uint8
+}
+
+type chardevLines struct {
+  fd    int
+  count int
+}
+
+func ioctl(fd int, request uintptr, arg unsafe.Pointer) error {
+  _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), request, uintptr(arg))
+  if errno != 0 {
+    return errno
+  }
+  return nil
+}
+
+// Request takes lines of the chip (e.g. /dev/gpiochip0) as outputs set to
+// values or as inputs if values are nil
+func Request(chip string, offsets []uint32, values []bool, label string) (Lines, error) {
+  if len(offsets) == 0 || len(offsets) > GPIOHANDLES_MAX {
+    return nil, fmt.Errorf("Wrong number of GPIO lines: %d", len(offsets))
+  }
+  file, err := os.Open(chip)
+  if err != nil {
+    return nil, err
+  }
+  defer file.Close()
+
+  request := handleRequest{lines: uint32(len(offsets)), flags: GPIOHANDLE_REQUEST_INPUT}
+  copy(request.lineOffsets[:], offsets)
+  copy(request.consumerLabel[:len(request.consumerLabel) - 1], label)
+  if values != nil {
+    request.flags = GPIOHANDLE_REQUEST_OUTPUT
+    for i, value := range values {
+      if value {
+        request.defaultValues[i] = 1
+      }
+    }
+  }
+  if err = ioctl(int(file.Fd()), GPIO_GET_LINEHANDLE_IOCTL, unsafe.Pointer(&request)); err != nil {
+    return nil, fmt.Errorf("GPIO lines %v of %s: %v", offsets, chip, err)
+  }
+  return &chardevLines{int(request.fd), len(offsets)}, nil
+}
+
+func (l *chardevLines) Get() ([]bool, error) {
+  data := handleData{}
+  if err := ioctl(l.fd, GPIOHANDLE_GET_LINE_VALUES_IOCTL, unsafe.Pointer(&data)); err != nil {
+    return nil, err
+  }
+  values := make([]bool, l.count)
+  for i := range values {
+    values[i] = data.values[i] != 0
+  }
+  return values, nil
+}
+
+func (l *chardevLines) Set(values []bool) error {
+  data := handleData{}
+  for i, value := range values {
+    if value {
+      data.values[i] = 1
+    }
+  }
+  return ioctl(l.fd, GPIOHANDLE_SET_LINE_VALUES_IOCTL, unsafe.Pointer(&data))
+}
+
+func (l *chardevLines) Close() error {
+  return syscall.Close(l.fd)
+}
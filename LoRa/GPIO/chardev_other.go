@@ -0,0 +1,8 @@
+// +build !linux
+
+package GPIO
+
+// Request isn't available without Linux GPIO character device
+func Request(chip string, offsets []uint32, values []bool, label string) (Lines, error) {
+  return nil, NotSupported
+}
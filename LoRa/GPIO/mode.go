@@ -0,0 +1,71 @@
+package GPIO
+
+import (
+  "e22config/LoRa"
+  "time"
+)
+
+const (
+  MODE_SWITCH_DELAY = 100 * time.Millisecond   // module settling time without AUX monitoring
+)
+
+// ModePins drives M0/M1 pins wired to GPIO lines
+type ModePins struct {
+  Aux   LoRa.AuxMonitor   // mode switch completion, MODE_SWITCH_DELAY is waited if nil
+  lines Lines
+  mode  LoRa.Mode
+}
+
+// NewModePins requests M0 and M1 lines of the chip and sets Normal mode
+func NewModePins(chip string, m0, m1 uint32) (*ModePins, error) {
+  lines, err := Request(chip, []uint32{m0, m1}, []bool{false, false}, "e22config M0/M1")
+  if err != nil {
+    return nil, err
+  }
+  return &ModePins{lines: lines, mode: LoRa.MODE_NORMAL}, nil
+}
+
+func (p *ModePins) Mode() LoRa.Mode {
+  return p.mode
+}
+
+// SetMode changes M0/M1 levels and waits for the module to switch
+func (p *ModePins) SetMode(mode LoRa.Mode) error {
+  m0, m1 := mode.Pins()
+  if err := p.lines.Set([]bool{m0, m1}); err != nil {
+    return err
+  }
+  p.mode = mode
+  if p.Aux == nil {
+    time.Sleep(MODE_SWITCH_DELAY)
+    return nil
+  }
+  // module pulls AUX low while switching
+  time.Sleep(LoRa.AUX_POLL_INTERVAL)
+  return LoRa.WaitReady(p.Aux, LoRa.AUX_TIMEOUT)
+}
+
+// Close releases GPIO lines
+func (p *ModePins) Close() error {
+  return p.lines.Close()
+}
+
+// FakeModePins records mode switches instead of driving pins
+type FakeModePins struct {
+  Current LoRa.Mode
+  History []LoRa.Mode
+  Err     error       // returned by SetMode if set
+}
+
+func (p *FakeModePins) Mode() LoRa.Mode {
+  return p.Current
+}
+
+func (p *FakeModePins) SetMode(mode LoRa.Mode) error {
+  if p.Err != nil {
+    return p.Err
+  }
+  p.Current = mode
+  p.History = append(p.History, mode)
+  return nil
+}
@@ -1 +1,48 @@
 package LoRa
+
+import (
+  "fmt"
+  "sort"
+)
+
+// Model describes module variant supported by the tool
+type Model interface {
+  Name() string             // e.g. E22-400T30D
+  Family() string           // E22, E31, E32
+  Powers() []int            // dBm, in power bits order
+  AirRates() []int          // bps, in air rate bits order
+  WORCycles() []int         // ms, WOR cycle or wake-up time in bits order
+  Plan() FrequencyPlan      // channels and their frequencies
+}
+
+var (
+  models = map[string]Model{}
+)
+
+// Register adds model to registry; it's called from init() of family packages
+func Register(model Model) {
+  if _, ok := models[model.Name()]; ok {
+    panic("LoRa: model " + model.Name() + " registered twice")
+  }
+  models[model.Name()] = model
+}
+
+// Models returns registered models sorted by name
+func Models() []Model {
+  list := []Model{}
+  for _, model := range models {
+    list = append(list, model)
+  }
+  sort.Slice(list, func(i, j int) bool {
+    return list[i].Name() < list[j].Name()
+  })
+  return list
+}
+
+// Find returns registered model by name
+func Find(name string) (Model, error) {
+  if model, ok := models[name]; ok {
+    return model, nil
+  }
+  return nil, fmt.Errorf("Unknown module model: %s", name)
+}
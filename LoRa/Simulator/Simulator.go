@@ -0,0 +1,334 @@
+package Simulator
+
+import (
+  "e22config/LoRa"
+  "e22config/LoRa/E22"
+  "e22config/LoRa/Transport"
+  "io"
+  "net/url"
+  "strconv"
+  "sync"
+)
+
+const (
+  REGISTERS = 0x100
+  FIRMWARE_MAJOR = 1
+  FIRMWARE_MINOR = 0
+)
+
+// Module is in-memory E22 module behind the Transport interface: every
+// Write is taken as one UART burst and answered the way the module does.
+// Module also implements LoRa.ModeController and LoRa.AuxMonitor, so it can
+// be given to E22.Device as Pins and Aux
+type Module struct {
+  Model *E22.Model
+  Transmit func(packet []byte)   // called with data sent in normal mode, dropped if nil
+  Noise byte                     // channel noise register value
+  mutex sync.Mutex
+  cond *sync.Cond
+  flash []byte                   // saved registers
+  ram []byte                     // active registers
+  mode LoRa.Mode
+  rssi byte                      // RSSI of the last received packet
+  out []byte                     // bytes waiting to be read from module UART
+  closed bool
+}
+
+var (
+  modules = map[string]*Module{}    // sim:// modules keep settings between openings
+  modulesMutex sync.Mutex
+)
+
+func init() {
+  Transport.Register("sim", func(u *url.URL) (LoRa.Transport, error) {
+    modulesMutex.Lock()
+    defer modulesMutex.Unlock()
+    if module, ok := modules[u.Host]; ok {
+      return module.open(), nil
+    }
+    m, err := LoRa.Find(u.Host)
+    if err != nil {
+      return nil, err
+    }
+    model, ok := m.(*E22.Model)
+    if !ok {
+      return nil, &LoRa.ConfigError{Field: "simulated model", Value: u.Host}
+    }
+    modules[u.Host] = New(model)
+    return modules[u.Host].open(), nil
+  })
+}
+
+// New returns module of model with factory settings in configuration mode
+func New(model *E22.Model) *Module {
+  if model == nil {
+    model = E22.DEFAULT_MODEL
+  }
+  m := &Module{Model: model, mode: LoRa.MODE_CONFIGURATION, Noise: 0xA0}
+  m.cond = sync.NewCond(&m.mutex)
+  m.flash = make([]byte, REGISTERS)
+  cfg := E22.DefaultConfig()
+  cfg.Power = model.Powers()[0]
+  cfg.Channel = model.Plan().DefaultChannel
+  args, err := model.MarshalConfig(cfg)
+  if err != nil {
+    panic(err)
+  }
+  copy(m.flash, args)
+  // PID layout of real modules is unknown, the block only differs by model
+  family, _ := strconv.ParseUint(model.Family()[1:], 16, 8)
+  copy(m.flash[E22.REGISTER_PID0[0]:], []byte{0, byte(family), byte(model.Plan().Band / 10),
+    byte(model.Powers()[0]), FIRMWARE_MAJOR, FIRMWARE_MINOR, 0})
+  m.ram = append([]byte{}, m.flash...)
+  return m
+}
+
+// PowerCycle restarts module: temporary settings are replaced by saved ones
+func (m *Module) PowerCycle() {
+  m.mutex.Lock()
+  defer m.mutex.Unlock()
+  copy(m.ram, m.flash)
+  m.out = nil
+}
+
+// Registers returns copy of active registers
+func (m *Module) Registers() []byte {
+  m.mutex.Lock()
+  defer m.mutex.Unlock()
+  return append([]byte{}, m.ram...)
+}
+
+// Saved returns copy of registers saved in flash
+func (m *Module) Saved() []byte {
+  m.mutex.Lock()
+  defer m.mutex.Unlock()
+  return append([]byte{}, m.flash...)
+}
+
+// Config decodes active configuration registers
+func (m *Module) Config() (E22.Config, error) {
+  return m.Model.UnmarshalConfig(m.Registers()[:E22.CONFIG_LENGTH])
+}
+
+func (m *Module) Mode() LoRa.Mode {
+  m.mutex.Lock()
+  defer m.mutex.Unlock()
+  return m.mode
+}
+
+func (m *Module) SetMode(mode LoRa.Mode) error {
+  m.mutex.Lock()
+  defer m.mutex.Unlock()
+  m.mode = mode
+  return nil
+}
+
+// Ready reports AUX high, simulated module is never busy
+func (m *Module) Ready() (bool, error) {
+  return true, nil
+}
+
+// listening reports if module receives from the air (normal or WOR mode)
+func (m *Module) listening() bool {
+  m.mutex.Lock()
+  defer m.mutex.Unlock()
+  return !m.closed && (m.mode == LoRa.MODE_NORMAL || m.mode == LoRa.MODE_WOR)
+}
+
+// Deliver puts packet received from the air to module UART; RSSI byte is
+// appended if it's enabled in REG3
+func (m *Module) Deliver(packet []byte, rssi byte) {
+  m.mutex.Lock()
+  defer m.mutex.Unlock()
+  if m.closed || (m.mode != LoRa.MODE_NORMAL && m.mode != LoRa.MODE_WOR) {
+    return
+  }
+  m.rssi = rssi
+  m.out = append(m.out, packet...)
+  if m.ram[E22.REGISTER_REG3[0]] & E22.MASK_RSSI == E22.RSSI_ENABLE {
+    m.out = append(m.out, rssi)
+  }
+  m.cond.Broadcast()
+}
+
+func (m *Module) Read(p []byte) (int, error) {
+  m.mutex.Lock()
+  defer m.mutex.Unlock()
+  for len(m.out) == 0 && !m.closed {
+    m.cond.Wait()
+  }
+  if len(m.out) == 0 {
+    return 0, io.EOF
+  }
+  n := copy(p, m.out)
+  m.out = m.out[n:]
+  return n, nil
+}
+
+func (m *Module) Write(p []byte) (int, error) {
+  m.mutex.Lock()
+  if m.closed {
+    m.mutex.Unlock()
+    return 0, io.ErrClosedPipe
+  }
+  frame := append([]byte{}, p...)
+  var packet []byte
+  switch m.mode {
+  case LoRa.MODE_CONFIGURATION:
+    m.reply(m.configure(frame))
+  case LoRa.MODE_NORMAL, LoRa.MODE_WOR:
+    if response, ok := m.query(frame); ok {
+      m.reply(response)
+    } else {
+      packet = frame
+    }
+  }
+  // sleep mode ignores UART
+  transmit := m.Transmit
+  m.mutex.Unlock()
+  if packet != nil && transmit != nil {
+    transmit(packet)
+  }
+  return len(p), nil
+}
+
+func (m *Module) Close() error {
+  m.mutex.Lock()
+  defer m.mutex.Unlock()
+  m.closed = true
+  m.cond.Broadcast()
+  return nil
+}
+
+// session is one opening of module, closing it keeps module running
+type session struct {
+  module *Module
+  closed bool
+}
+
+// open starts new session dropping output nobody has read
+func (m *Module) open() *session {
+  m.mutex.Lock()
+  defer m.mutex.Unlock()
+  m.out = nil
+  return &session{module: m}
+}
+
+func (s *session) Read(p []byte) (int, error) {
+  m := s.module
+  m.mutex.Lock()
+  defer m.mutex.Unlock()
+  for len(m.out) == 0 && !m.closed && !s.closed {
+    m.cond.Wait()
+  }
+  if s.closed || len(m.out) == 0 {
+    return 0, io.EOF
+  }
+  n := copy(p, m.out)
+  m.out = m.out[n:]
+  return n, nil
+}
+
+func (s *session) Write(p []byte) (int, error) {
+  s.module.mutex.Lock()
+  closed := s.closed
+  s.module.mutex.Unlock()
+  if closed {
+    return 0, io.ErrClosedPipe
+  }
+  return s.module.Write(p)
+}
+
+func (s *session) Close() error {
+  s.module.mutex.Lock()
+  defer s.module.mutex.Unlock()
+  s.closed = true
+  s.module.cond.Broadcast()
+  return nil
+}
+
+func (m *Module) reply(response []byte) {
+  m.out = append(m.out, response...)
+  m.cond.Broadcast()
+}
+
+// configure executes register command of configuration mode
+func (m *Module) configure(frame []byte) []byte {
+  rejected := E22.RESPONSE_WRONG_FORMAT[:]
+  if len(frame) < E22.HEADER_LENGTH {
+    return rejected
+  }
+  command, address, length := frame[0], frame[1], int(frame[2])
+  data := frame[E22.HEADER_LENGTH:]
+  if length == 0 || int(address) + length > REGISTERS {
+    return rejected
+  }
+  switch command {
+  case E22.COMMAND_GET_REGISTER[0]:
+    if len(data) != 0 {
+      return rejected
+    }
+  case E22.COMMAND_SET_REGISTER[0], E22.COMMAND_SET_TEMPORARY_REGISTER[0]:
+    if len(data) != length {
+      return rejected
+    }
+    for i := range data {
+      if !writable(address + byte(i)) {
+        return rejected
+      }
+    }
+    copy(m.ram[address:], data)
+    if command == E22.COMMAND_SET_REGISTER[0] {
+      copy(m.flash[address:], data)
+    }
+  default:
+    return rejected
+  }
+  response := []byte{E22.COMMAND_GET_REGISTER[0], address, byte(length)}
+  for i := 0; i < length; i++ {
+    register := address + byte(i)
+    if readable(register) {
+      response = append(response, m.ram[register])
+    } else {
+      response = append(response, 0)
+    }
+  }
+  return response
+}
+
+// query answers RSSI command of transmission modes; other data is sent
+// over the air
+func (m *Module) query(frame []byte) ([]byte, bool) {
+  command := E22.COMMAND_READ_RSSI[:]
+  if len(frame) != len(command) + 2 || string(frame[:len(command)]) != string(command) {
+    return nil, false
+  }
+  if m.ram[E22.REGISTER_REG1[0]] & E22.MASK_AMBIENT_NOISE != E22.AMBIENT_NOISE_ENABLE {
+    return nil, false
+  }
+  address, length := frame[len(command)], int(frame[len(command) + 1])
+  values := []byte{m.Noise, m.rssi}
+  if int(address) + length > len(values) || length == 0 {
+    return E22.RESPONSE_WRONG_FORMAT[:], true
+  }
+  response := []byte{E22.COMMAND_GET_REGISTER[0], address, byte(length)}
+  return append(response, values[address:int(address) + length]...), true
+}
+
+func readable(address byte) bool {
+  for _, item := range E22.REGISTER_MAP {
+    if item.Address == address {
+      return item.Readable
+    }
+  }
+  return false
+}
+
+func writable(address byte) bool {
+  for _, item := range E22.REGISTER_MAP {
+    if item.Address == address {
+      return item.Writable
+    }
+  }
+  return false
+}
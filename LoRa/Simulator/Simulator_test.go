@@ -0,0 +1,243 @@
+package Simulator
+
+import (
+  "bytes"
+  "e22config/LoRa"
+  "e22config/LoRa/E22"
+  "e22config/LoRa/Transport"
+  "testing"
+  "time"
+)
+
+// device opens E22 driver on module, module pins are driven by the driver
+func device(t *testing.T, m *Module) *E22.Device {
+  d := &E22.Device{Model: m.Model, Pins: m, Timeout: 200 * time.Millisecond}
+  d.Aux = m
+  if err := d.Open(m.open()); err != nil {
+    t.Fatal(err)
+  }
+  t.Cleanup(func() {
+    d.Close()
+  })
+  return d
+}
+
+// exchange writes frame to module UART and reads the answer
+func exchange(t *testing.T, m *Module, frame []byte) []byte {
+  if _, err := m.Write(frame); err != nil {
+    t.Fatal(err)
+  }
+  buffer := make([]byte, 512)
+  n, err := m.Read(buffer)
+  if err != nil {
+    t.Fatal(err)
+  }
+  return buffer[:n]
+}
+
+func decoded(t *testing.T, m *E22.Model, registers []byte) E22.Config {
+  cfg, err := m.UnmarshalConfig(registers[:E22.CONFIG_LENGTH])
+  if err != nil {
+    t.Fatal(err)
+  }
+  return cfg
+}
+
+func TestFactoryConfig(t *testing.T) {
+  for _, model := range []*E22.Model{E22.E22_400T30D, E22.E22_900T22D} {
+    d := device(t, New(model))
+    cfg, err := d.ReadConfig()
+    if err != nil {
+      t.Fatal(err)
+    }
+    expected := E22.DefaultConfig()
+    expected.Power = model.Powers()[0]
+    expected.Channel = model.Plan().DefaultChannel
+    if cfg != expected {
+      t.Fatalf("%s: read %+v, expected %+v", model.Name(), cfg, expected)
+    }
+  }
+}
+
+func TestFlashAndRAM(t *testing.T) {
+  m := New(nil)
+  d := device(t, m)
+  saved := E22.DefaultConfig()
+  saved.Address = 0x1234
+  saved.NetID = 7
+  saved.CryptKey = 0xBEEF
+  if err := d.WriteConfig(saved); err != nil {
+    t.Fatal(err)
+  }
+  applied := saved
+  applied.Channel = 0x20
+  applied.FixedTransmission = true
+  if err := d.ApplyConfig(applied); err != nil {
+    t.Fatal(err)
+  }
+  if cfg := decoded(t, m.Model, m.Registers()); cfg != applied {
+    t.Fatalf("active %+v, expected %+v", cfg, applied)
+  }
+  if cfg := decoded(t, m.Model, m.Saved()); cfg != saved {
+    t.Fatalf("saved %+v, expected %+v", cfg, saved)
+  }
+  cfg, err := d.ReadConfig()
+  if err != nil {
+    t.Fatal(err)
+  }
+  if cfg.Channel != applied.Channel || !cfg.FixedTransmission {
+    t.Fatalf("read %+v before power cycle", cfg)
+  }
+
+  m.PowerCycle()
+  if cfg := decoded(t, m.Model, m.Registers()); cfg != saved {
+    t.Fatalf("active %+v after power cycle, expected %+v", cfg, saved)
+  }
+  if cfg, err = d.ReadConfig(); err != nil {
+    t.Fatal(err)
+  }
+  // crypt key isn't read back
+  expected := saved
+  expected.CryptKey = 0
+  if cfg != expected {
+    t.Fatalf("read %+v after power cycle, expected %+v", cfg, expected)
+  }
+}
+
+func TestCryptIsWriteOnly(t *testing.T) {
+  m := New(nil)
+  d := device(t, m)
+  if err := d.WriteRegisters(E22.REGISTER_CRYPT_H[0], []byte{0x12, 0x34}); err != nil {
+    t.Fatal(err)
+  }
+  data, err := d.ReadRegisters(E22.REGISTER_CRYPT_H[0], 2)
+  if err != nil {
+    t.Fatal(err)
+  }
+  if !bytes.Equal(data, []byte{0, 0}) {
+    t.Fatalf("crypt registers read as % X", data)
+  }
+  if saved := m.Saved()[E22.REGISTER_CRYPT_H[0]:E22.REGISTER_CRYPT_L[0] + 1]; !bytes.Equal(saved, []byte{0x12, 0x34}) {
+    t.Fatalf("crypt registers saved as % X", saved)
+  }
+}
+
+func TestWrongFormat(t *testing.T) {
+  m := New(nil)
+  rejected := E22.RESPONSE_WRONG_FORMAT[:]
+  for _, frame := range [][]byte{
+    {0xC1, 0x00},                     // short header
+    {0xC5, 0x00, 0x01},               // unknown command
+    {0xC1, 0x00, 0x00},               // no registers
+    {0xC1, 0xFF, 0x02},               // beyond register space
+    {0xC1, 0x00, 0x01, 0x00},         // data in read command
+    {0xC0, 0x00, 0x02, 0x00},         // data shorter than length
+  } {
+    if response := exchange(t, m, frame); !bytes.Equal(response, rejected) {
+      t.Fatalf("% X answered with % X", frame, response)
+    }
+  }
+  before := m.Saved()
+  write := append([]byte{E22.COMMAND_SET_REGISTER[0], E22.REGISTER_PID0[0], 1}, 0x55)
+  if response := exchange(t, m, write); !bytes.Equal(response, rejected) {
+    t.Fatalf("PID write answered with % X", response)
+  }
+  if !bytes.Equal(m.Saved(), before) {
+    t.Fatal("PID write changed registers")
+  }
+
+  d := device(t, m)
+  if err := d.WriteRegisters(E22.REGISTER_PID0[0], []byte{0x55}); err != E22.WrongFormat {
+    t.Fatalf("PID write error %v, expected WrongFormat", err)
+  }
+}
+
+func TestProductInfo(t *testing.T) {
+  for _, item := range LoRa.Models() {
+    model, ok := item.(*E22.Model)
+    if !ok {
+      continue
+    }
+    m := New(model)
+    info, err := device(t, m).ReadProductInfo()
+    if err != nil {
+      t.Fatal(err)
+    }
+    pid := m.Saved()[E22.REGISTER_PID0[0]:E22.REGISTER_PID6[0] + 1]
+    if !bytes.Equal(info.Raw, pid) {
+      t.Fatalf("%s: raw % X, expected % X", model.Name(), info.Raw, pid)
+    }
+  }
+}
+
+func TestSimScheme(t *testing.T) {
+  open := func() *E22.Device {
+    transport, err := Transport.Dial("sim://E22-400T22D")
+    if err != nil {
+      t.Fatal(err)
+    }
+    d := &E22.Device{Model: E22.E22_400T22D, Timeout: 200 * time.Millisecond}
+    if err := d.Open(transport); err != nil {
+      t.Fatal(err)
+    }
+    return d
+  }
+  d := open()
+  cfg, err := d.ReadConfig()
+  if err != nil {
+    t.Fatal(err)
+  }
+  cfg.Address = 0x4242
+  if err := d.WriteConfig(cfg); err != nil {
+    t.Fatal(err)
+  }
+  d.Close()
+
+  // settings are kept between openings
+  d = open()
+  defer d.Close()
+  read, err := d.ReadConfig()
+  if err != nil {
+    t.Fatal(err)
+  }
+  if read.Address != 0x4242 {
+    t.Fatalf("address 0x%04X after reopening", read.Address)
+  }
+  if _, err := Transport.Dial("sim://E32-433T30D"); err == nil {
+    t.Fatal("E32 model is simulated")
+  }
+}
+
+func TestReadRSSI(t *testing.T) {
+  m := New(nil)
+  m.Noise = 0xA0
+  d := device(t, m)
+  if _, err := d.ReadRSSI(); err == nil {
+    t.Fatal("RSSI is read with ambient noise disabled")
+  }
+  cfg := E22.DefaultConfig()
+  cfg.Power = m.Model.Powers()[0]
+  cfg.AmbientNoise = true
+  if err := d.WriteConfig(cfg); err != nil {
+    t.Fatal(err)
+  }
+  rssi, err := d.ReadRSSI()
+  if err != nil {
+    t.Fatal(err)
+  }
+  // nothing is received yet
+  if rssi != (E22.RSSI{Noise: -96, Last: 0}) {
+    t.Fatalf("read %+v before packet", rssi)
+  }
+  m.SetMode(LoRa.MODE_NORMAL)
+  m.Deliver([]byte("hello"), 0x90)
+  if packet, err := d.Receive(time.Second); err != nil || string(packet.Data) != "hello" {
+    t.Fatalf("received %+v, error %v", packet, err)
+  }
+  if rssi, err = d.ReadRSSI(); err != nil {
+    t.Fatal(err)
+  }
+  if rssi != (E22.RSSI{Noise: -96, Last: -112}) {
+    t.Fatalf("read %+v after packet", rssi)
+  }
+}
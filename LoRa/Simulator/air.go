@@ -0,0 +1,185 @@
+package Simulator
+
+import (
+  "bytes"
+  "e22config/LoRa/E22"
+  "math/rand"
+  "sync"
+)
+
+const (
+  DEFAULT_RSSI byte = 0xC8    // -56 dBm
+)
+
+// frame is a sub-packet on the air
+type frame struct {
+  netID byte
+  address uint16      // target
+  channel byte
+  airRate int
+  key uint16
+  payload []byte
+  repeated map[*Module]bool   // repeaters which have forwarded the frame
+}
+
+// Air is simulated radio medium of attached modules. A packet reaches
+// modules in transmission modes on the same channel, air rate and NETID
+// whose address matches the target (or either one is BROADCAST_ADDRESS).
+// Data is split by sender sub-packet length, crypt key mismatch garbles it,
+// repeaters forward it between NETIDs given by their ADDH and ADDL
+type Air struct {
+  Loss float64                          // probability of sub-packet loss, 0..1
+  RSSI func(from, to *Module) byte      // RSSI register value of received packet, DEFAULT_RSSI if nil
+  random *rand.Rand
+  modules []*Module
+  mutex sync.Mutex
+}
+
+// NewAir returns lossless medium; seed makes losses reproducible
+func NewAir(seed int64) *Air {
+  return &Air{random: rand.New(rand.NewSource(seed))}
+}
+
+// Attach connects module to the medium
+func (a *Air) Attach(modules ...*Module) {
+  a.mutex.Lock()
+  defer a.mutex.Unlock()
+  for _, m := range modules {
+    sender := m
+    sender.mutex.Lock()
+    sender.Transmit = func(packet []byte) {
+      a.transmit(sender, packet)
+    }
+    sender.mutex.Unlock()
+    a.modules = append(a.modules, sender)
+  }
+}
+
+// Detach disconnects module from the medium
+func (a *Air) Detach(m *Module) {
+  a.mutex.Lock()
+  defer a.mutex.Unlock()
+  for i, item := range a.modules {
+    if item == m {
+      a.modules = append(a.modules[:i], a.modules[i + 1:]...)
+      break
+    }
+  }
+  m.mutex.Lock()
+  m.Transmit = nil
+  m.mutex.Unlock()
+}
+
+// transmit sends data written to sender UART in normal mode
+func (a *Air) transmit(sender *Module, data []byte) {
+  cfg, err := sender.Config()
+  if err != nil {
+    return
+  }
+  address, channel := cfg.Address, cfg.Channel
+  if cfg.FixedTransmission {
+    if len(data) < E22.TARGET_HEADER_LENGTH {
+      return
+    }
+    address = uint16(data[0]) << 8 | uint16(data[1])
+    channel = data[2]
+    data = data[E22.TARGET_HEADER_LENGTH:]
+  }
+  a.mutex.Lock()
+  defer a.mutex.Unlock()
+  for len(data) > 0 {
+    n := cfg.SubPacket
+    if n > len(data) {
+      n = len(data)
+    }
+    a.propagate(sender, &frame{
+      netID: cfg.NetID,
+      address: address,
+      channel: channel,
+      airRate: cfg.AirRate,
+      key: cfg.CryptKey,
+      payload: data[:n],
+      repeated: map[*Module]bool{},
+    })
+    data = data[n:]
+  }
+}
+
+// propagate delivers frame to every module able to hear it
+func (a *Air) propagate(sender *Module, f *frame) {
+  for _, receiver := range a.modules {
+    if receiver == sender || !receiver.listening() {
+      continue
+    }
+    cfg, err := receiver.Config()
+    if err != nil || cfg.Channel != f.channel || cfg.AirRate != f.airRate {
+      continue
+    }
+    if a.random.Float64() < a.Loss {
+      continue
+    }
+    if cfg.Repeater {
+      a.repeat(receiver, cfg, f)
+      continue
+    }
+    if cfg.NetID != f.netID {
+      continue
+    }
+    if f.address != cfg.Address && f.address != E22.BROADCAST_ADDRESS && cfg.Address != E22.MONITOR_ADDRESS {
+      continue
+    }
+    payload := garble(f.payload, f.key ^ cfg.CryptKey)
+    if bytes.HasPrefix(payload, E22.COMMAND_WIRELESS_CONFIG[:]) {
+      a.configure(sender, receiver, payload)
+      continue
+    }
+    rssi := DEFAULT_RSSI
+    if a.RSSI != nil {
+      rssi = a.RSSI(sender, receiver)
+    }
+    receiver.Deliver(payload, rssi)
+  }
+}
+
+// repeat forwards frame from NETID ADDH to NETID ADDL and back
+func (a *Air) repeat(repeater *Module, cfg E22.Config, f *frame) {
+  if f.repeated[repeater] {
+    return
+  }
+  from, to := byte(cfg.Address >> 8), byte(cfg.Address)
+  switch f.netID {
+  case from:
+  case to:
+    from, to = to, from
+  default:
+    return
+  }
+  f.repeated[repeater] = true
+  forwarded := *f
+  forwarded.netID = to
+  a.propagate(repeater, &forwarded)
+}
+
+// configure executes wireless configuration command on receiver and passes
+// the answer back to sender
+func (a *Air) configure(sender, receiver *Module, payload []byte) {
+  prefix := E22.COMMAND_WIRELESS_CONFIG[:]
+  receiver.mutex.Lock()
+  response := receiver.configure(payload[len(prefix):])
+  receiver.mutex.Unlock()
+  sender.mutex.Lock()
+  defer sender.mutex.Unlock()
+  sender.reply(append(append([]byte{}, prefix...), response...))
+}
+
+// garble simulates decryption with a wrong key, data is kept if keys match
+func garble(data []byte, mismatch uint16) []byte {
+  out := append([]byte{}, data...)
+  if mismatch == 0 {
+    return out
+  }
+  for i := range out {
+    out[i] ^= byte(mismatch >> (8 * uint(i % 2))) | 1
+  }
+  return out
+}
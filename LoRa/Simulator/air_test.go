@@ -0,0 +1,223 @@
+package Simulator
+
+import (
+  "bytes"
+  "e22config/LoRa"
+  "e22config/LoRa/E22"
+  "testing"
+  "time"
+)
+
+// node attaches module in normal mode with factory settings changed by change
+func node(t *testing.T, air *Air, change func(*E22.Config)) *Module {
+  m := New(nil)
+  cfg, err := m.Config()
+  if err != nil {
+    t.Fatal(err)
+  }
+  if change != nil {
+    change(&cfg)
+  }
+  args, err := m.Model.MarshalConfig(cfg)
+  if err != nil {
+    t.Fatal(err)
+  }
+  m.mutex.Lock()
+  copy(m.ram, args)
+  copy(m.flash, args)
+  m.mode = LoRa.MODE_NORMAL
+  m.mutex.Unlock()
+  air.Attach(m)
+  return m
+}
+
+// received takes bytes waiting in module UART
+func received(m *Module) []byte {
+  m.mutex.Lock()
+  defer m.mutex.Unlock()
+  out := m.out
+  m.out = nil
+  return out
+}
+
+func send(t *testing.T, m *Module, data []byte) {
+  if _, err := m.Write(data); err != nil {
+    t.Fatal(err)
+  }
+}
+
+func TestTransparentFiltering(t *testing.T) {
+  air := NewAir(1)
+  sender := node(t, air, nil)
+  same := node(t, air, nil)
+  channel := node(t, air, func(c *E22.Config) { c.Channel = 0x18 })
+  netID := node(t, air, func(c *E22.Config) { c.NetID = 1 })
+  address := node(t, air, func(c *E22.Config) { c.Address = 1 })
+  airRate := node(t, air, func(c *E22.Config) { c.AirRate = 9600 })
+  monitor := node(t, air, func(c *E22.Config) { c.Address = E22.MONITOR_ADDRESS })
+  sleeping := node(t, air, nil)
+  sleeping.SetMode(LoRa.MODE_CONFIGURATION)
+
+  send(t, sender, []byte("hello"))
+  for _, m := range []*Module{same, monitor} {
+    if data := received(m); string(data) != "hello" {
+      t.Fatalf("received %q, expected hello", data)
+    }
+  }
+  for name, m := range map[string]*Module{"channel": channel, "NETID": netID, "address": address,
+      "air rate": airRate, "configuration mode": sleeping} {
+    if data := received(m); len(data) != 0 {
+      t.Fatalf("module with other %s received %q", name, data)
+    }
+  }
+  if data := received(sender); len(data) != 0 {
+    t.Fatalf("sender received %q", data)
+  }
+}
+
+func TestFixedTransmission(t *testing.T) {
+  air := NewAir(1)
+  sender := node(t, air, func(c *E22.Config) { c.FixedTransmission = true })
+  target := node(t, air, func(c *E22.Config) { c.Address = 2; c.Channel = 0x10 })
+  other := node(t, air, func(c *E22.Config) { c.Address = 3; c.Channel = 0x10 })
+  monitor := node(t, air, func(c *E22.Config) { c.Address = E22.MONITOR_ADDRESS; c.Channel = 0x10 })
+
+  send(t, sender, append(E22.TargetHeader(2, 0x10), "one"...))
+  if data := received(target); string(data) != "one" {
+    t.Fatalf("target received %q", data)
+  }
+  if data := received(other); len(data) != 0 {
+    t.Fatalf("other module received %q", data)
+  }
+  if data := received(monitor); string(data) != "one" {
+    t.Fatalf("monitor received %q", data)
+  }
+
+  send(t, sender, append(E22.TargetHeader(E22.BROADCAST_ADDRESS, 0x10), "all"...))
+  for _, m := range []*Module{target, other, monitor} {
+    if data := received(m); string(data) != "all" {
+      t.Fatalf("broadcast received as %q", data)
+    }
+  }
+}
+
+func TestCryptMismatch(t *testing.T) {
+  air := NewAir(1)
+  sender := node(t, air, func(c *E22.Config) { c.CryptKey = 0x1234 })
+  same := node(t, air, func(c *E22.Config) { c.CryptKey = 0x1234 })
+  other := node(t, air, func(c *E22.Config) { c.CryptKey = 0x4321 })
+  send(t, sender, []byte("secret"))
+  if data := received(same); string(data) != "secret" {
+    t.Fatalf("module with the same key received %q", data)
+  }
+  data := received(other)
+  if len(data) != len("secret") || string(data) == "secret" {
+    t.Fatalf("module with other key received %q", data)
+  }
+}
+
+func TestRepeater(t *testing.T) {
+  air := NewAir(1)
+  sender := node(t, air, func(c *E22.Config) { c.NetID = 1 })
+  receiver := node(t, air, func(c *E22.Config) { c.NetID = 2 })
+  send(t, sender, []byte("far"))
+  if data := received(receiver); len(data) != 0 {
+    t.Fatalf("other NETID received %q without repeater", data)
+  }
+
+  // repeater forwards NETID ADDH to NETID ADDL and back
+  node(t, air, func(c *E22.Config) { c.Repeater = true; c.Address = 0x0102 })
+  send(t, sender, []byte("far"))
+  if data := received(receiver); string(data) != "far" {
+    t.Fatalf("repeated packet received as %q", data)
+  }
+  send(t, receiver, []byte("back"))
+  if data := received(sender); string(data) != "back" {
+    t.Fatalf("repeated answer received as %q", data)
+  }
+}
+
+func TestSubPackets(t *testing.T) {
+  air := NewAir(1)
+  air.RSSI = func(from, to *Module) byte {
+    return 0x90
+  }
+  sender := node(t, air, func(c *E22.Config) { c.SubPacket = 32 })
+  receiver := node(t, air, func(c *E22.Config) { c.RSSI = true })
+  data := bytes.Repeat([]byte("0123456789"), 7)
+  send(t, sender, data)
+  // every sub-packet is followed by RSSI byte
+  expected := []byte{}
+  for _, part := range [][]byte{data[:32], data[32:64], data[64:]} {
+    expected = append(append(expected, part...), 0x90)
+  }
+  if out := received(receiver); !bytes.Equal(out, expected) {
+    t.Fatalf("received % X, expected % X", out, expected)
+  }
+}
+
+func TestLoss(t *testing.T) {
+  pattern := func(seed int64) []byte {
+    air := NewAir(seed)
+    air.Loss = 0.5
+    sender := node(t, air, nil)
+    receiver := node(t, air, nil)
+    for i := 0; i < 100; i++ {
+      send(t, sender, []byte{byte(i)})
+    }
+    return received(receiver)
+  }
+  first := pattern(42)
+  if len(first) == 0 || len(first) == 100 {
+    t.Fatalf("%d of 100 packets received with 50%% loss", len(first))
+  }
+  if second := pattern(42); !bytes.Equal(first, second) {
+    t.Fatal("losses differ with the same seed")
+  }
+}
+
+func TestPacketConnRoundTrip(t *testing.T) {
+  air := NewAir(1)
+  fixed := func(address uint16) func(*E22.Config) {
+    return func(c *E22.Config) {
+      c.Address = address
+      c.FixedTransmission = true
+      c.RSSI = true
+    }
+  }
+  conn := func(m *Module) *E22.PacketConn {
+    d := &E22.Device{Model: m.Model, Pins: m, Timeout: 200 * time.Millisecond}
+    if err := d.Open(m.open()); err != nil {
+      t.Fatal(err)
+    }
+    if _, err := d.ReadConfig(); err != nil {
+      t.Fatal(err)
+    }
+    c, err := E22.NewPacketConn(d)
+    if err != nil {
+      t.Fatal(err)
+    }
+    t.Cleanup(func() {
+      c.Close()
+    })
+    return c
+  }
+  a := conn(node(t, air, fixed(1)))
+  b := conn(node(t, air, fixed(2)))
+
+  if _, err := a.WriteTo([]byte("ping"), b.LocalAddr()); err != nil {
+    t.Fatal(err)
+  }
+  b.SetReadDeadline(time.Now().Add(time.Second))
+  buffer := make([]byte, 64)
+  n, from, err := b.ReadFrom(buffer)
+  if err != nil {
+    t.Fatal(err)
+  }
+  if string(buffer[:n]) != "ping" {
+    t.Fatalf("received %q", buffer[:n])
+  }
+  if from.String() != (&E22.Addr{Address: E22.BROADCAST_ADDRESS, Channel: 0x17}).String() {
+    t.Fatalf("received from %s", from)
+  }
+}
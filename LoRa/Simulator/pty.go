@@ -0,0 +1,71 @@
+package Simulator
+
+import (
+  "os"
+  "time"
+)
+
+const (
+  PTY_RETRY = 100 * time.Millisecond    // master read retry while slave is not open
+)
+
+// PTY exposes module on a pseudo terminal which can be opened as a serial
+// port by any program, e.g. the configuration utility itself
+type PTY struct {
+  Path string       // slave device, e.g. /dev/pts/3
+  module *Module
+  master *os.File
+  done chan struct{}
+}
+
+// ServePTY bridges module to a new pseudo terminal until Close
+func ServePTY(module *Module) (*PTY, error) {
+  master, path, err := openPTY()
+  if err != nil {
+    return nil, err
+  }
+  p := &PTY{path, module, master, make(chan struct{})}
+  go p.input()
+  go p.output()
+  return p, nil
+}
+
+// input passes terminal writes to module; master read fails while the slave
+// is closed, it's retried until Close
+func (p *PTY) input() {
+  buffer := make([]byte, 1024)
+  for {
+    n, err := p.master.Read(buffer)
+    if n > 0 {
+      p.module.Write(buffer[:n])
+    }
+    if err != nil {
+      select {
+      case <-p.done:
+        return
+      case <-time.After(PTY_RETRY):
+      }
+    }
+  }
+}
+
+// output passes module responses and received packets to terminal
+func (p *PTY) output() {
+  buffer := make([]byte, 1024)
+  for {
+    n, err := p.module.Read(buffer)
+    if n > 0 {
+      p.master.Write(buffer[:n])
+    }
+    if err != nil {
+      return
+    }
+  }
+}
+
+// Close closes pseudo terminal and the module
+func (p *PTY) Close() error {
+  close(p.done)
+  p.module.Close()
+  return p.master.Close()
+}
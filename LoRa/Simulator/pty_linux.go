@@ -0,0 +1,54 @@
+// +build linux
+
+package Simulator
+
+import (
+  "fmt"
+  "os"
+  "syscall"
+  "unsafe"
+)
+
+const (
+  TIOCGPTN = 0x80045430
+  TIOCSPTLCK = 0x40045431
+)
+
+func ioctl(fd, request, arg uintptr) error {
+  if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, request, arg); errno != 0 {
+    return errno
+  }
+  return nil
+}
+
+// openPTY opens pseudo terminal master in raw mode and returns slave path
+func openPTY() (*os.File, string, error) {
+  master, err := os.OpenFile("/dev/ptmx", os.O_RDWR, 0)
+  if err != nil {
+    return nil, "", err
+  }
+  fd := master.Fd()
+  unlock := int32(0)
+  var number uint32
+  if err = ioctl(fd, TIOCSPTLCK, uintptr(unsafe.Pointer(&unlock))); err == nil {
+    err = ioctl(fd, TIOCGPTN, uintptr(unsafe.Pointer(&number)))
+  }
+  if err == nil {
+    // no echo and line editing, bytes pass as is
+    var termios syscall.Termios
+    if err = ioctl(fd, syscall.TCGETS, uintptr(unsafe.Pointer(&termios))); err == nil {
+      termios.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP |
+        syscall.INLCR | syscall.IGNCR | syscall.ICRNL | syscall.IXON
+      termios.Oflag &^= syscall.OPOST
+      termios.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+      termios.Cflag &^= syscall.CSIZE | syscall.PARENB
+      termios.Cflag |= syscall.CS8
+      err = ioctl(fd, syscall.TCSETS, uintptr(unsafe.Pointer(&termios)))
+    }
+  }
+  if err != nil {
+    master.Close()
+    return nil, "", err
+  }
+  return master, fmt.Sprintf("/dev/pts/%d", number), nil
+}
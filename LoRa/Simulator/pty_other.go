@@ -0,0 +1,12 @@
+// +build !linux
+
+package Simulator
+
+import (
+  "errors"
+  "os"
+)
+
+func openPTY() (*os.File, string, error) {
+  return nil, "", errors.New("Pseudo terminals are supported on Linux only")
+}
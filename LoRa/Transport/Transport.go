@@ -0,0 +1,89 @@
+package Transport
+
+import (
+  "e22config/LoRa"
+  "fmt"
+  "net/url"
+  "sort"
+  "strings"
+  "sync"
+  "go.bug.st/serial.v1"
+)
+
+// Dialer opens transport for the URL of its scheme
+type Dialer func(u *url.URL) (LoRa.Transport, error)
+
+var (
+  dialers = map[string]Dialer{}
+  mutex sync.Mutex
+)
+
+// Register adds dialer of URL scheme; it panics if scheme is registered twice
+func Register(scheme string, dialer Dialer) {
+  mutex.Lock()
+  defer mutex.Unlock()
+  if _, ok := dialers[scheme]; ok {
+    panic("Transport scheme registered twice: " + scheme)
+  }
+  dialers[scheme] = dialer
+}
+
+// Schemes returns registered URL schemes sorted by name
+func Schemes() []string {
+  mutex.Lock()
+  defer mutex.Unlock()
+  schemes := []string{}
+  for scheme := range dialers {
+    schemes = append(schemes, scheme)
+  }
+  sort.Strings(schemes)
+  return schemes
+}
+
+// IsURL reports if device is given as URL rather than a local port path
+func IsURL(device string) bool {
+  return strings.Contains(device, "://")
+}
+
+// Dial opens transport given as URL
+func Dial(device string) (LoRa.Transport, error) {
+  u, err := url.Parse(device)
+  if err != nil {
+    return nil, err
+  }
+  mutex.Lock()
+  dialer, ok := dialers[u.Scheme]
+  mutex.Unlock()
+  if !ok {
+    return nil, fmt.Errorf("Unknown transport scheme: %s", u.Scheme)
+  }
+  return dialer(u)
+}
+
+// Configurable is transport which UART settings can be changed: local
+// serial port or remote one through RFC 2217
+type Configurable interface {
+  SetMode(mode *serial.Mode) error
+}
+
+// ConfigMode returns UART settings of module configuration mode (9600 8N1)
+func ConfigMode() *serial.Mode {
+  return &serial.Mode{BaudRate: 9600, DataBits: 8, Parity: serial.NoParity, StopBits: serial.OneStopBit}
+}
+
+// UARTMode returns UART settings of module rate and parity ("8N1", "8O1", "8E1")
+func UARTMode(rate int, parity string) (*serial.Mode, error) {
+  parities := map[string]serial.Parity{
+    "8N1": serial.NoParity,
+    "8O1": serial.OddParity,
+    "8E1": serial.EvenParity,
+  }
+  bits, ok := parities[parity]
+  if !ok {
+    return nil, &LoRa.ConfigError{Field: "UARTParity", Value: parity}
+  }
+  if rate <= 0 {
+    return nil, &LoRa.ConfigError{Field: "UARTRate", Value: rate}
+  }
+  return &serial.Mode{BaudRate: rate, DataBits: 8, Parity: bits, StopBits: serial.OneStopBit}, nil
+}
@@ -0,0 +1,172 @@
+package Transport
+
+import (
+  "encoding/binary"
+  "io"
+  "os"
+  "time"
+)
+
+const (
+  LINKTYPE_USER0 = 147    // private link type, Wireshark maps it to a dissector in DLT_User table
+
+  PCAP_MAGIC = 0xA1B2C3D4
+  PCAP_SNAPLEN = 0xFFFF
+  PCAP_DIRECTION_TX = 0   // first byte of pcap record: host to module
+  PCAP_DIRECTION_RX = 1   // module to host
+
+  PCAPNG_SECTION_HEADER = 0x0A0D0D0A
+  PCAPNG_INTERFACE_DESCRIPTION = 0x00000001
+  PCAPNG_ENHANCED_PACKET = 0x00000006
+  PCAPNG_BYTE_ORDER_MAGIC = 0x1A2B3C4D
+  PCAPNG_OPT_END = 0
+  PCAPNG_OPT_COMMENT = 1
+  PCAPNG_OPT_FLAGS = 2
+  PCAPNG_FLAG_INBOUND = 1
+  PCAPNG_FLAG_OUTBOUND = 2
+)
+
+var (
+  order = binary.LittleEndian
+)
+
+// PcapWriter writes entries as classic pcap records of LINKTYPE_USER0;
+// pcap has no direction field, so every record starts with
+// PCAP_DIRECTION_TX or PCAP_DIRECTION_RX byte
+type PcapWriter struct {
+  w io.Writer
+  start time.Time
+}
+
+// NewPcapWriter writes pcap header unless w is a non-empty file appended to
+func NewPcapWriter(w io.Writer) (*PcapWriter, error) {
+  p := &PcapWriter{w, time.Now()}
+  if file, ok := w.(*os.File); ok {
+    if info, err := file.Stat(); err == nil && info.Size() > 0 {
+      return p, nil
+    }
+  }
+  header := make([]byte, 24)
+  order.PutUint32(header[0:], PCAP_MAGIC)
+  order.PutUint16(header[4:], 2)
+  order.PutUint16(header[6:], 4)
+  order.PutUint32(header[16:], PCAP_SNAPLEN)
+  order.PutUint32(header[20:], LINKTYPE_USER0)
+  _, err := w.Write(header)
+  return p, err
+}
+
+func (p *PcapWriter) WriteEntry(entry Entry) error {
+  direction := byte(PCAP_DIRECTION_RX)
+  if entry.Direction == DIRECTION_TX {
+    direction = PCAP_DIRECTION_TX
+  }
+  timestamp := p.start.Add(entry.Time)
+  record := make([]byte, 16, 17 + len(entry.Data))
+  order.PutUint32(record[0:], uint32(timestamp.Unix()))
+  order.PutUint32(record[4:], uint32(timestamp.Nanosecond() / 1000))
+  order.PutUint32(record[8:], uint32(len(entry.Data) + 1))
+  order.PutUint32(record[12:], uint32(len(entry.Data) + 1))
+  record = append(record, direction)
+  record = append(record, entry.Data...)
+  _, err := p.w.Write(record)
+  return err
+}
+
+// Close closes underlying writer if it can be closed
+func (p *PcapWriter) Close() error {
+  if closer, ok := p.w.(io.Closer); ok {
+    return closer.Close()
+  }
+  return nil
+}
+
+// PcapngWriter writes entries as pcapng enhanced packets of LINKTYPE_USER0
+// with inbound (RX) or outbound (TX) direction flag and optional comment
+type PcapngWriter struct {
+  w io.Writer
+  start time.Time
+  annotate func(Entry) string
+}
+
+// NewPcapngWriter starts pcapng section; annotate makes packet comments,
+// none are written if it's nil. Appending sections to a file is valid pcapng
+func NewPcapngWriter(w io.Writer, annotate func(Entry) string) (*PcapngWriter, error) {
+  p := &PcapngWriter{w, time.Now(), annotate}
+  section := make([]byte, 16)
+  order.PutUint32(section[0:], PCAPNG_BYTE_ORDER_MAGIC)
+  order.PutUint16(section[4:], 1)
+  order.PutUint16(section[6:], 0)
+  order.PutUint64(section[8:], 0xFFFFFFFFFFFFFFFF)   // section length is not specified
+  if err := p.block(PCAPNG_SECTION_HEADER, section); err != nil {
+    return nil, err
+  }
+  iface := make([]byte, 8)
+  order.PutUint16(iface[0:], LINKTYPE_USER0)
+  order.PutUint32(iface[4:], 0)
+  if err := p.block(PCAPNG_INTERFACE_DESCRIPTION, iface); err != nil {
+    return nil, err
+  }
+  return p, nil
+}
+
+// block writes pcapng block padding body to 32 bits
+func (p *PcapngWriter) block(kind uint32, body []byte) error {
+  body = pad(body)
+  length := uint32(12 + len(body))
+  out := make([]byte, 8, length)
+  order.PutUint32(out[0:], kind)
+  order.PutUint32(out[4:], length)
+  out = append(out, body...)
+  out = append(out, 0, 0, 0, 0)
+  order.PutUint32(out[len(out) - 4:], length)
+  _, err := p.w.Write(out)
+  return err
+}
+
+func pad(data []byte) []byte {
+  for len(data) % 4 != 0 {
+    data = append(data, 0)
+  }
+  return data
+}
+
+func option(code uint16, value []byte) []byte {
+  out := make([]byte, 4)
+  order.PutUint16(out[0:], code)
+  order.PutUint16(out[2:], uint16(len(value)))
+  return pad(append(out, value...))
+}
+
+func (p *PcapngWriter) WriteEntry(entry Entry) error {
+  // default interface resolution is microseconds
+  timestamp := uint64(p.start.Add(entry.Time).UnixNano() / 1000)
+  body := make([]byte, 20)
+  order.PutUint32(body[0:], 0)
+  order.PutUint32(body[4:], uint32(timestamp >> 32))
+  order.PutUint32(body[8:], uint32(timestamp))
+  order.PutUint32(body[12:], uint32(len(entry.Data)))
+  order.PutUint32(body[16:], uint32(len(entry.Data)))
+  body = pad(append(body, entry.Data...))
+  flags := make([]byte, 4)
+  order.PutUint32(flags, PCAPNG_FLAG_INBOUND)
+  if entry.Direction == DIRECTION_TX {
+    order.PutUint32(flags, PCAPNG_FLAG_OUTBOUND)
+  }
+  body = append(body, option(PCAPNG_OPT_FLAGS, flags)...)
+  if p.annotate != nil {
+    if comment := p.annotate(entry); comment != "" {
+      body = append(body, option(PCAPNG_OPT_COMMENT, []byte(comment))...)
+    }
+  }
+  body = append(body, option(PCAPNG_OPT_END, nil)...)
+  return p.block(PCAPNG_ENHANCED_PACKET, body)
+}
+
+// Close closes underlying writer if it can be closed
+func (p *PcapngWriter) Close() error {
+  if closer, ok := p.w.(io.Closer); ok {
+    return closer.Close()
+  }
+  return nil
+}
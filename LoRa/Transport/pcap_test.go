@@ -0,0 +1,75 @@
+package Transport
+
+import (
+  "bytes"
+  "encoding/hex"
+  "strings"
+  "testing"
+  "time"
+)
+
+// golden decodes hex bytes separated by spaces
+func golden(t *testing.T, text string) []byte {
+  data, err := hex.DecodeString(strings.Join(strings.Fields(text), ""))
+  if err != nil {
+    t.Fatal(err)
+  }
+  return data
+}
+
+var (
+  captureStart = time.Unix(1000, 0)
+  txEntry = Entry{Time: 1500 * time.Millisecond, Direction: DIRECTION_TX, Data: []byte{0xC1, 0x00, 0x07}}
+  rxEntry = Entry{Time: 2 * time.Second, Direction: DIRECTION_RX, Data: []byte("hello")}
+)
+
+func TestPcapGolden(t *testing.T) {
+  out := &bytes.Buffer{}
+  p, err := NewPcapWriter(out)
+  if err != nil {
+    t.Fatal(err)
+  }
+  p.start = captureStart
+  for _, entry := range []Entry{txEntry, rxEntry} {
+    if err := p.WriteEntry(entry); err != nil {
+      t.Fatal(err)
+    }
+  }
+  expected := golden(t, `
+    d4c3b2a1 0200 0400 00000000 00000000 ffff0000 93000000
+    e9030000 20a10700 04000000 04000000 00 c10007
+    ea030000 00000000 06000000 06000000 01 68656c6c6f`)
+  if !bytes.Equal(out.Bytes(), expected) {
+    t.Fatalf("written\n% X\nexpected\n% X", out.Bytes(), expected)
+  }
+}
+
+func TestPcapngGolden(t *testing.T) {
+  out := &bytes.Buffer{}
+  annotate := func(entry Entry) string {
+    if entry.Direction == DIRECTION_TX {
+      return "tx"
+    }
+    return ""
+  }
+  p, err := NewPcapngWriter(out, annotate)
+  if err != nil {
+    t.Fatal(err)
+  }
+  p.start = captureStart
+  for _, entry := range []Entry{txEntry, rxEntry} {
+    if err := p.WriteEntry(entry); err != nil {
+      t.Fatal(err)
+    }
+  }
+  expected := golden(t, `
+    0a0d0d0a 1c000000 4d3c2b1a 0100 0000 ffffffffffffffff 1c000000
+    01000000 14000000 9300 0000 00000000 14000000
+    06000000 38000000 00000000 00000000 60adb13b 03000000 03000000 c1000700
+      0200 0400 02000000 0100 0200 74780000 0000 0000 38000000
+    06000000 34000000 00000000 00000000 804eb93b 05000000 05000000 68656c6c6f000000
+      0200 0400 01000000 0000 0000 34000000`)
+  if !bytes.Equal(out.Bytes(), expected) {
+    t.Fatalf("written\n% X\nexpected\n% X", out.Bytes(), expected)
+  }
+}
@@ -0,0 +1,288 @@
+package Transport
+
+import (
+  "bufio"
+  "bytes"
+  "e22config/LoRa"
+  "encoding/hex"
+  "fmt"
+  "io"
+  "net/url"
+  "os"
+  "strconv"
+  "strings"
+  "sync"
+  "time"
+)
+
+const (
+  DIRECTION_TX = "TX"   // host to module
+  DIRECTION_RX = "RX"   // module to host
+  CAPTURE_HEADER = "# e22config capture"
+)
+
+// Entry is one captured transport chunk
+type Entry struct {
+  Time time.Duration    // since capture start
+  Direction string      // DIRECTION_TX, DIRECTION_RX
+  Data []byte
+}
+
+func (e Entry) String() string {
+  return fmt.Sprintf("%.6f %s % X", e.Time.Seconds(), e.Direction, e.Data)
+}
+
+// MismatchError is returned by Replayer when written bytes differ from capture
+type MismatchError struct {
+  Entry int
+  Expected []byte
+  Received []byte
+}
+
+func (e *MismatchError) Error() string {
+  if e.Expected == nil {
+    return fmt.Sprintf("Replay is over at entry %d: % X written", e.Entry, e.Received)
+  }
+  return fmt.Sprintf("Replay mismatch at entry %d: % X written, % X expected", e.Entry, e.Received, e.Expected)
+}
+
+// EntryWriter stores captured entries in some format
+type EntryWriter interface {
+  WriteEntry(entry Entry) error
+}
+
+// TextWriter writes entries as text lines "<seconds> <TX|RX> <hex bytes>"
+// which ParseCapture reads back
+type TextWriter struct {
+  w io.Writer
+}
+
+// NewTextWriter starts text capture with a header comment
+func NewTextWriter(w io.Writer) *TextWriter {
+  fmt.Fprintf(w, "%s %s\n", CAPTURE_HEADER, time.Now().Format(time.RFC3339))
+  return &TextWriter{w}
+}
+
+func (t *TextWriter) WriteEntry(entry Entry) error {
+  _, err := fmt.Fprintln(t.w, entry.String())
+  return err
+}
+
+// Close closes underlying writer if it can be closed
+func (t *TextWriter) Close() error {
+  if closer, ok := t.w.(io.Closer); ok {
+    return closer.Close()
+  }
+  return nil
+}
+
+// Recorder passes data to Transport and stores every chunk to capture
+type Recorder struct {
+  LoRa.Transport
+  capture EntryWriter
+  start time.Time
+  mutex sync.Mutex
+}
+
+// Record wraps transport writing its traffic to text capture
+func Record(transport LoRa.Transport, capture io.Writer) *Recorder {
+  return RecordTo(transport, NewTextWriter(capture))
+}
+
+// RecordTo wraps transport storing its traffic to capture
+func RecordTo(transport LoRa.Transport, capture EntryWriter) *Recorder {
+  return &Recorder{Transport: transport, capture: capture, start: time.Now()}
+}
+
+// RecordFile wraps transport appending its traffic to capture file; format
+// is chosen by extension: .pcapng, .pcap or text otherwise
+func RecordFile(transport LoRa.Transport, path string, annotate func(Entry) string) (*Recorder, error) {
+  file, err := os.OpenFile(path, os.O_WRONLY | os.O_APPEND | os.O_CREATE, 0644)
+  if err != nil {
+    return nil, err
+  }
+  var capture EntryWriter
+  switch {
+  case strings.HasSuffix(path, ".pcapng"):
+    capture, err = NewPcapngWriter(file, annotate)
+  case strings.HasSuffix(path, ".pcap"):
+    capture, err = NewPcapWriter(file)
+  default:
+    capture = NewTextWriter(file)
+  }
+  if err != nil {
+    file.Close()
+    return nil, err
+  }
+  return RecordTo(transport, capture), nil
+}
+
+func (r *Recorder) record(direction string, data []byte) {
+  r.mutex.Lock()
+  defer r.mutex.Unlock()
+  r.capture.WriteEntry(Entry{time.Since(r.start), direction, append([]byte{}, data...)})
+}
+
+func (r *Recorder) Write(data []byte) (int, error) {
+  n, err := r.Transport.Write(data)
+  if n > 0 {
+    r.record(DIRECTION_TX, data[:n])
+  }
+  return n, err
+}
+
+func (r *Recorder) Read(buffer []byte) (int, error) {
+  n, err := r.Transport.Read(buffer)
+  if n > 0 {
+    r.record(DIRECTION_RX, buffer[:n])
+  }
+  return n, err
+}
+
+// Close closes transport and capture if it can be closed
+func (r *Recorder) Close() error {
+  err := r.Transport.Close()
+  if closer, ok := r.capture.(io.Closer); ok {
+    if closeErr := closer.Close(); err == nil {
+      err = closeErr
+    }
+  }
+  return err
+}
+
+// ParseCapture reads capture entries; comment lines start with #
+func ParseCapture(capture io.Reader) ([]Entry, error) {
+  entries := []Entry{}
+  scanner := bufio.NewScanner(capture)
+  for line := 1; scanner.Scan(); line++ {
+    text := strings.TrimSpace(scanner.Text())
+    if text == "" || strings.HasPrefix(text, "#") {
+      continue
+    }
+    fields := strings.Fields(text)
+    if len(fields) < 2 || (fields[1] != DIRECTION_TX && fields[1] != DIRECTION_RX) {
+      return nil, fmt.Errorf("Capture line %d is garbled: %s", line, text)
+    }
+    seconds, err := strconv.ParseFloat(fields[0], 64)
+    if err != nil {
+      return nil, fmt.Errorf("Capture line %d has bad time: %v", line, err)
+    }
+    data, err := hex.DecodeString(strings.Join(fields[2:], ""))
+    if err != nil {
+      return nil, fmt.Errorf("Capture line %d has bad data: %v", line, err)
+    }
+    entries = append(entries, Entry{time.Duration(seconds * float64(time.Second)), fields[1], data})
+  }
+  return entries, scanner.Err()
+}
+
+// Replayer plays module side of a capture: written bytes are checked against
+// TX entries, RX entries following them become readable. Chunks may be
+// split differently than in capture
+type Replayer struct {
+  entries []Entry
+  next int             // entry to play
+  expected []byte      // rest of TX entry being written
+  out []byte           // RX data not read yet
+  mutex sync.Mutex
+  cond *sync.Cond
+  closed bool
+}
+
+func init() {
+  Register("replay", func(u *url.URL) (LoRa.Transport, error) {
+    return ReplayFile(u.Path)
+  })
+}
+
+// Replay returns transport playing capture entries
+func Replay(entries []Entry) *Replayer {
+  r := &Replayer{entries: entries}
+  r.cond = sync.NewCond(&r.mutex)
+  r.release()
+  return r
+}
+
+// ReplayFile returns transport playing capture file
+func ReplayFile(path string) (*Replayer, error) {
+  file, err := os.Open(path)
+  if err != nil {
+    return nil, err
+  }
+  defer file.Close()
+  entries, err := ParseCapture(file)
+  if err != nil {
+    return nil, err
+  }
+  return Replay(entries), nil
+}
+
+// release makes RX entries up to the next TX one readable
+func (r *Replayer) release() {
+  for r.next < len(r.entries) && r.entries[r.next].Direction == DIRECTION_RX {
+    r.out = append(r.out, r.entries[r.next].Data...)
+    r.next++
+  }
+  r.cond.Broadcast()
+}
+
+// Done reports if the whole capture is played and read
+func (r *Replayer) Done() bool {
+  r.mutex.Lock()
+  defer r.mutex.Unlock()
+  return r.next >= len(r.entries) && len(r.expected) == 0 && len(r.out) == 0
+}
+
+func (r *Replayer) Write(p []byte) (int, error) {
+  r.mutex.Lock()
+  defer r.mutex.Unlock()
+  if r.closed {
+    return 0, io.ErrClosedPipe
+  }
+  written := 0
+  for written < len(p) {
+    if len(r.expected) == 0 {
+      if r.next >= len(r.entries) {
+        return written, &MismatchError{r.next, nil, p[written:]}
+      }
+      r.expected = r.entries[r.next].Data
+      r.next++
+    }
+    n := len(p) - written
+    if n > len(r.expected) {
+      n = len(r.expected)
+    }
+    if !bytes.Equal(p[written:written + n], r.expected[:n]) {
+      return written, &MismatchError{r.next - 1, r.expected, p[written:written + n]}
+    }
+    written += n
+    r.expected = r.expected[n:]
+    if len(r.expected) == 0 {
+      r.release()
+    }
+  }
+  return written, nil
+}
+
+// Read returns played RX data; io.EOF is returned when capture is over
+func (r *Replayer) Read(p []byte) (int, error) {
+  r.mutex.Lock()
+  defer r.mutex.Unlock()
+  for len(r.out) == 0 && !r.closed && (r.next < len(r.entries) || len(r.expected) > 0) {
+    r.cond.Wait()
+  }
+  if len(r.out) == 0 {
+    return 0, io.EOF
+  }
+  n := copy(p, r.out)
+  r.out = r.out[n:]
+  return n, nil
+}
+
+func (r *Replayer) Close() error {
+  r.mutex.Lock()
+  defer r.mutex.Unlock()
+  r.closed = true
+  r.cond.Broadcast()
+  return nil
+}
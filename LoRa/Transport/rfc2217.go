@@ -0,0 +1,228 @@
+package Transport
+
+import (
+  "e22config/LoRa"
+  "encoding/binary"
+  "net"
+  "net/url"
+  "sync"
+  "go.bug.st/serial.v1"
+)
+
+var (
+  COM_PORT_SET_BAUDRATE byte          = 1
+  COM_PORT_SET_DATASIZE byte          = 2
+  COM_PORT_SET_PARITY byte            = 3
+  COM_PORT_SET_STOPSIZE byte          = 4
+  COM_PORT_SET_CONTROL byte           = 5
+  COM_PORT_NOTIFY_LINESTATE byte      = 6
+  COM_PORT_NOTIFY_MODEMSTATE byte     = 7
+  COM_PORT_PURGE_DATA byte            = 12
+  COM_PORT_SERVER byte                = 100   // added to the command in server responses
+
+  PURGE_RECEIVE byte                  = 1
+  PURGE_TRANSMIT byte                 = 2
+  PURGE_BOTH byte                     = 3
+)
+
+// RFC2217 is Telnet COM port control link to a serial server; UART
+// settings of the remote port can be changed
+type RFC2217 struct {
+  conn net.Conn
+  decoder telnet
+  raw []byte
+  pending []byte      // decoded data not returned by Read
+  mutex sync.Mutex    // conn writes, sent options
+  sent map[[2]byte]bool
+  state sync.Mutex
+  mode serial.Mode    // settings acknowledged by server
+  modem byte          // last modem state notified by server
+}
+
+func init() {
+  Register("rfc2217", func(u *url.URL) (LoRa.Transport, error) {
+    return DialRFC2217(u.Host, nil)
+  })
+}
+
+// DialRFC2217 connects to serial server at host:port and sets its port to
+// mode, ConfigMode() if nil
+func DialRFC2217(address string, mode *serial.Mode) (*RFC2217, error) {
+  tcp, err := DialTCP(address)
+  if err != nil {
+    return nil, err
+  }
+  t := &RFC2217{conn: tcp.Conn, raw: make([]byte, 1024), sent: map[[2]byte]bool{}}
+  t.decoder.option = t.option
+  t.decoder.subnegotiation = t.response
+  err = t.negotiate([2]byte{WILL, OPTION_COM_PORT}, [2]byte{WILL, OPTION_BINARY},
+    [2]byte{DO, OPTION_BINARY}, [2]byte{DO, OPTION_SUPPRESS_GO_AHEAD})
+  if err == nil {
+    if mode == nil {
+      mode = ConfigMode()
+    }
+    err = t.SetMode(mode)
+  }
+  if err != nil {
+    tcp.Close()
+    return nil, err
+  }
+  return t, nil
+}
+
+// negotiate sends option commands not sent yet
+func (t *RFC2217) negotiate(commands ...[2]byte) error {
+  t.mutex.Lock()
+  defer t.mutex.Unlock()
+  out := []byte{}
+  for _, item := range commands {
+    if !t.sent[item] {
+      t.sent[item] = true
+      out = append(out, IAC, item[0], item[1])
+    }
+  }
+  if len(out) == 0 {
+    return nil
+  }
+  _, err := t.conn.Write(out)
+  return err
+}
+
+// option answers server option requests: binary transmission and
+// suppressed go-ahead are accepted, anything else is refused
+func (t *RFC2217) option(command, option byte) {
+  supported := option == OPTION_BINARY || option == OPTION_SUPPRESS_GO_AHEAD
+  switch command {
+  case DO:
+    if supported || option == OPTION_COM_PORT {
+      t.negotiate([2]byte{WILL, option})
+    } else {
+      t.negotiate([2]byte{WONT, option})
+    }
+  case WILL:
+    if supported {
+      t.negotiate([2]byte{DO, option})
+    } else {
+      t.negotiate([2]byte{DONT, option})
+    }
+  }
+}
+
+// response records settings acknowledged by server
+func (t *RFC2217) response(data []byte) {
+  if len(data) < 3 || data[0] != OPTION_COM_PORT || data[1] < COM_PORT_SERVER {
+    return
+  }
+  t.state.Lock()
+  defer t.state.Unlock()
+  value := data[2]
+  switch data[1] - COM_PORT_SERVER {
+  case COM_PORT_SET_BAUDRATE:
+    if len(data) >= 6 {
+      t.mode.BaudRate = int(binary.BigEndian.Uint32(data[2:6]))
+    }
+  case COM_PORT_SET_DATASIZE:
+    t.mode.DataBits = int(value)
+  case COM_PORT_SET_PARITY:
+    t.mode.Parity = serial.Parity(value - 1)
+  case COM_PORT_SET_STOPSIZE:
+    t.mode.StopBits = stopBits(value)
+  case COM_PORT_NOTIFY_MODEMSTATE:
+    t.modem = value
+  }
+}
+
+// command sends COM port subnegotiation
+func (t *RFC2217) command(code byte, value ...byte) error {
+  t.mutex.Lock()
+  defer t.mutex.Unlock()
+  _, err := t.conn.Write(subnegotiation(OPTION_COM_PORT, append([]byte{code}, value...)...))
+  return err
+}
+
+// SetMode changes remote port settings; Mode reports them once the server
+// acknowledges
+func (t *RFC2217) SetMode(mode *serial.Mode) error {
+  baud := make([]byte, 4)
+  binary.BigEndian.PutUint32(baud, uint32(mode.BaudRate))
+  if err := t.command(COM_PORT_SET_BAUDRATE, baud...); err != nil {
+    return err
+  }
+  if err := t.command(COM_PORT_SET_DATASIZE, byte(mode.DataBits)); err != nil {
+    return err
+  }
+  if err := t.command(COM_PORT_SET_PARITY, byte(mode.Parity) + 1); err != nil {
+    return err
+  }
+  return t.command(COM_PORT_SET_STOPSIZE, stopSize(mode.StopBits))
+}
+
+// Mode returns settings acknowledged by server
+func (t *RFC2217) Mode() serial.Mode {
+  t.state.Lock()
+  defer t.state.Unlock()
+  return t.mode
+}
+
+// ModemState returns the last modem state byte notified by server
+func (t *RFC2217) ModemState() byte {
+  t.state.Lock()
+  defer t.state.Unlock()
+  return t.modem
+}
+
+// Purge discards buffered data of remote port
+func (t *RFC2217) Purge() error {
+  return t.command(COM_PORT_PURGE_DATA, PURGE_BOTH)
+}
+
+func (t *RFC2217) Read(p []byte) (int, error) {
+  for len(t.pending) == 0 {
+    n, err := t.conn.Read(t.raw)
+    t.pending = t.decoder.decode(t.raw[:n], t.pending)
+    if err != nil {
+      if len(t.pending) > 0 {
+        break
+      }
+      return 0, err
+    }
+  }
+  n := copy(p, t.pending)
+  t.pending = t.pending[n:]
+  return n, nil
+}
+
+func (t *RFC2217) Write(p []byte) (int, error) {
+  t.mutex.Lock()
+  defer t.mutex.Unlock()
+  if _, err := t.conn.Write(escape(p)); err != nil {
+    return 0, err
+  }
+  return len(p), nil
+}
+
+func (t *RFC2217) Close() error {
+  return t.conn.Close()
+}
+
+// stopSize converts stop bits to RFC 2217 value
+func stopSize(bits serial.StopBits) byte {
+  switch bits {
+  case serial.TwoStopBits:
+    return 2
+  case serial.OnePointFiveStopBits:
+    return 3
+  }
+  return 1
+}
+
+// stopBits converts RFC 2217 stop size to stop bits
+func stopBits(size byte) serial.StopBits {
+  switch size {
+  case 2:
+    return serial.TwoStopBits
+  case 3:
+    return serial.OnePointFiveStopBits
+  }
+  return serial.OneStopBit
+}
@@ -0,0 +1,202 @@
+package Transport
+
+import (
+  "e22config/LoRa"
+  "encoding/binary"
+  "net"
+  "sync"
+  "go.bug.st/serial.v1"
+)
+
+// RFC2217Server is in-process stand-in of a serial server for tests: one
+// client at a time is bridged to Backend, port settings requested by the
+// client are acknowledged, recorded and passed to Backend if it is
+// Configurable
+type RFC2217Server struct {
+  Backend LoRa.Transport
+  listener net.Listener
+  mutex sync.Mutex    // conn and its writes
+  conn net.Conn       // current client
+  state sync.Mutex
+  mode serial.Mode
+}
+
+// NewRFC2217Server listens at address ("127.0.0.1:0" picks a free port)
+// and serves clients until Close; backend is owned by the server
+func NewRFC2217Server(address string, backend LoRa.Transport) (*RFC2217Server, error) {
+  listener, err := net.Listen("tcp", address)
+  if err != nil {
+    return nil, err
+  }
+  s := &RFC2217Server{Backend: backend, listener: listener, mode: *ConfigMode()}
+  go s.accept()
+  go s.pump()
+  return s, nil
+}
+
+// Addr returns host:port the server listens at
+func (s *RFC2217Server) Addr() string {
+  return s.listener.Addr().String()
+}
+
+// URL returns device URL of the server for Dial
+func (s *RFC2217Server) URL() string {
+  return "rfc2217://" + s.Addr()
+}
+
+// Mode returns port settings requested by the client
+func (s *RFC2217Server) Mode() serial.Mode {
+  s.state.Lock()
+  defer s.state.Unlock()
+  return s.mode
+}
+
+// Close stops listening, drops the client and closes Backend
+func (s *RFC2217Server) Close() error {
+  err := s.listener.Close()
+  s.mutex.Lock()
+  if s.conn != nil {
+    s.conn.Close()
+    s.conn = nil
+  }
+  s.mutex.Unlock()
+  if closeErr := s.Backend.Close(); err == nil {
+    err = closeErr
+  }
+  return err
+}
+
+// accept replaces current client with a new one
+func (s *RFC2217Server) accept() {
+  for {
+    conn, err := s.listener.Accept()
+    if err != nil {
+      return
+    }
+    s.mutex.Lock()
+    if s.conn != nil {
+      s.conn.Close()
+    }
+    s.conn = conn
+    s.mutex.Unlock()
+    go s.serve(conn)
+  }
+}
+
+// send writes to conn if it's still the current client
+func (s *RFC2217Server) send(conn net.Conn, data []byte) {
+  s.mutex.Lock()
+  defer s.mutex.Unlock()
+  if conn != nil && conn == s.conn {
+    conn.Write(data)
+  }
+}
+
+// pump passes Backend output to the current client
+func (s *RFC2217Server) pump() {
+  buffer := make([]byte, 1024)
+  for {
+    n, err := s.Backend.Read(buffer)
+    if n > 0 {
+      s.mutex.Lock()
+      conn := s.conn
+      s.mutex.Unlock()
+      s.send(conn, escape(buffer[:n]))
+    }
+    if err != nil {
+      return
+    }
+  }
+}
+
+// serve passes client data to Backend and answers its commands
+func (s *RFC2217Server) serve(conn net.Conn) {
+  defer conn.Close()
+  sent := map[[2]byte]bool{}
+  reply := func(command, option byte) {
+    if !sent[[2]byte{command, option}] {
+      sent[[2]byte{command, option}] = true
+      s.send(conn, []byte{IAC, command, option})
+    }
+  }
+  decoder := telnet{
+    option: func(command, option byte) {
+      supported := option == OPTION_BINARY || option == OPTION_SUPPRESS_GO_AHEAD
+      switch command {
+      case WILL:
+        if supported || option == OPTION_COM_PORT {
+          reply(DO, option)
+        } else {
+          reply(DONT, option)
+        }
+      case DO:
+        if supported {
+          reply(WILL, option)
+        } else {
+          reply(WONT, option)
+        }
+      }
+    },
+    subnegotiation: func(data []byte) {
+      if len(data) < 3 || data[0] != OPTION_COM_PORT || data[1] >= COM_PORT_SERVER {
+        return
+      }
+      answer := append([]byte{data[1] + COM_PORT_SERVER}, s.control(data[1], data[2:])...)
+      s.send(conn, subnegotiation(OPTION_COM_PORT, answer...))
+    },
+  }
+  raw := make([]byte, 1024)
+  for {
+    n, err := conn.Read(raw)
+    if data := decoder.decode(raw[:n], nil); len(data) > 0 {
+      if _, err := s.Backend.Write(data); err != nil {
+        return
+      }
+    }
+    if err != nil {
+      return
+    }
+  }
+}
+
+// control applies client COM port command and returns the answer value:
+// the current setting for port settings (value 0 only queries it), the
+// command value otherwise
+func (s *RFC2217Server) control(code byte, value []byte) []byte {
+  s.state.Lock()
+  answer := value
+  switch code {
+  case COM_PORT_SET_BAUDRATE:
+    if len(value) < 4 {
+      break
+    }
+    if rate := binary.BigEndian.Uint32(value); rate != 0 {
+      s.mode.BaudRate = int(rate)
+    }
+    answer = make([]byte, 4)
+    binary.BigEndian.PutUint32(answer, uint32(s.mode.BaudRate))
+  case COM_PORT_SET_DATASIZE:
+    if value[0] != 0 {
+      s.mode.DataBits = int(value[0])
+    }
+    answer = []byte{byte(s.mode.DataBits)}
+  case COM_PORT_SET_PARITY:
+    if value[0] != 0 {
+      s.mode.Parity = serial.Parity(value[0] - 1)
+    }
+    answer = []byte{byte(s.mode.Parity) + 1}
+  case COM_PORT_SET_STOPSIZE:
+    if value[0] != 0 {
+      s.mode.StopBits = stopBits(value[0])
+    }
+    answer = []byte{stopSize(s.mode.StopBits)}
+  }
+  mode := s.mode
+  s.state.Unlock()
+  if code >= COM_PORT_SET_BAUDRATE && code <= COM_PORT_SET_STOPSIZE {
+    if port, ok := s.Backend.(Configurable); ok {
+      port.SetMode(&mode)
+    }
+  }
+  return answer
+}
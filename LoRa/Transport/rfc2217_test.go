@@ -0,0 +1,155 @@
+package Transport
+
+import (
+  "bytes"
+  "io"
+  "net"
+  "sync"
+  "testing"
+  "time"
+  "go.bug.st/serial.v1"
+)
+
+// configurablePipe is server backend recording UART settings
+type configurablePipe struct {
+  net.Conn
+  mutex sync.Mutex
+  mode serial.Mode
+}
+
+func (p *configurablePipe) SetMode(mode *serial.Mode) error {
+  p.mutex.Lock()
+  defer p.mutex.Unlock()
+  p.mode = *mode
+  return nil
+}
+
+func (p *configurablePipe) Mode() serial.Mode {
+  p.mutex.Lock()
+  defer p.mutex.Unlock()
+  return p.mode
+}
+
+// eventually polls condition for a second
+func eventually(t *testing.T, what string, condition func() bool) {
+  deadline := time.Now().Add(time.Second)
+  for !condition() {
+    if time.Now().After(deadline) {
+      t.Fatalf("%s isn't reached", what)
+    }
+    time.Sleep(time.Millisecond)
+  }
+}
+
+func TestRFC2217Loopback(t *testing.T) {
+  backendHost, module := net.Pipe()
+  defer module.Close()
+  backend := &configurablePipe{Conn: backendHost}
+  s, err := NewRFC2217Server("127.0.0.1:0", backend)
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer s.Close()
+  transport, err := Dial(s.URL())
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer transport.Close()
+  client, ok := transport.(*RFC2217)
+  if !ok {
+    t.Fatalf("%T dialed", transport)
+  }
+  // acknowledgements are handled by client reads
+  received := make(chan []byte, 16)
+  go func() {
+    buffer := make([]byte, 64)
+    for {
+      n, err := client.Read(buffer)
+      if n > 0 {
+        received <- append([]byte{}, buffer[:n]...)
+      }
+      if err != nil {
+        close(received)
+        return
+      }
+    }
+  }()
+
+  mode, err := UARTMode(115200, "8E1")
+  if err != nil {
+    t.Fatal(err)
+  }
+  if err := client.SetMode(mode); err != nil {
+    t.Fatal(err)
+  }
+  eventually(t, "server mode", func() bool { return s.Mode() == *mode })
+  eventually(t, "backend mode", func() bool { return backend.Mode() == *mode })
+  eventually(t, "client mode", func() bool { return client.Mode() == *mode })
+
+  // zero values query settings, the server answers with current ones
+  for _, query := range [][]byte{{COM_PORT_SET_BAUDRATE, 0, 0, 0, 0}, {COM_PORT_SET_DATASIZE, 0},
+      {COM_PORT_SET_PARITY, 0}, {COM_PORT_SET_STOPSIZE, 0}} {
+    if err := client.command(query[0], query[1:]...); err != nil {
+      t.Fatal(err)
+    }
+  }
+  if err := client.Purge(); err != nil {
+    t.Fatal(err)
+  }
+  time.Sleep(50 * time.Millisecond)
+  if client.Mode() != *mode || s.Mode() != *mode {
+    t.Fatalf("mode after queries: client %+v, server %+v, expected %+v", client.Mode(), s.Mode(), *mode)
+  }
+
+  // 0xFF is doubled on the wire in both directions
+  out := []byte{0x01, IAC, 0x02, IAC, IAC}
+  if _, err := client.Write(out); err != nil {
+    t.Fatal(err)
+  }
+  got := make([]byte, len(out))
+  module.SetReadDeadline(time.Now().Add(time.Second))
+  if _, err := io.ReadFull(module, got); err != nil {
+    t.Fatal(err)
+  }
+  if !bytes.Equal(got, out) {
+    t.Fatalf("backend received % X, expected % X", got, out)
+  }
+  in := []byte{IAC, 0x00, IAC, SE, 0x03}
+  if _, err := module.Write(in); err != nil {
+    t.Fatal(err)
+  }
+  got = []byte{}
+  for len(got) < len(in) {
+    select {
+    case chunk, ok := <-received:
+      if !ok {
+        t.Fatalf("client received % X", got)
+      }
+      got = append(got, chunk...)
+    case <-time.After(time.Second):
+      t.Fatalf("client received % X", got)
+    }
+  }
+  if !bytes.Equal(got, in) {
+    t.Fatalf("client received % X, expected % X", got, in)
+  }
+}
+
+func TestEscape(t *testing.T) {
+  data := []byte{0x00, IAC, 0x10, IAC}
+  escaped := escape(data)
+  expected := []byte{0x00, IAC, IAC, 0x10, IAC, IAC}
+  if !bytes.Equal(escaped, expected) {
+    t.Fatalf("escaped % X, expected % X", escaped, expected)
+  }
+  decoder := telnet{}
+  if decoded := decoder.decode(escaped, nil); !bytes.Equal(decoded, data) {
+    t.Fatalf("decoded % X, expected % X", decoded, data)
+  }
+  // escaped IAC split between reads
+  decoded := decoder.decode(escaped[:2], nil)
+  decoded = decoder.decode(escaped[2:], decoded)
+  if !bytes.Equal(decoded, data) {
+    t.Fatalf("decoded by parts % X, expected % X", decoded, data)
+  }
+}
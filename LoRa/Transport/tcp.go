@@ -0,0 +1,50 @@
+package Transport
+
+import (
+  "e22config/LoRa"
+  "fmt"
+  "net"
+  "net/url"
+  "time"
+)
+
+const (
+  DIAL_TIMEOUT = 5 * time.Second
+  KEEP_ALIVE = 30 * time.Second
+)
+
+// TCP is raw socket link to a serial server (ser2net, etc.) passing bytes
+// to the module UART as is
+type TCP struct {
+  net.Conn
+}
+
+func init() {
+  Register("tcp", func(u *url.URL) (LoRa.Transport, error) {
+    return DialTCP(u.Host)
+  })
+}
+
+// DialTCP connects to serial server at host:port
+func DialTCP(address string) (*TCP, error) {
+  if address == "" {
+    return nil, fmt.Errorf("Serial server address is empty")
+  }
+  host, port, err := net.SplitHostPort(address)
+  if err != nil {
+    return nil, err
+  }
+  if host == "" || port == "" {
+    return nil, fmt.Errorf("Serial server address needs host and port: %s", address)
+  }
+  dialer := net.Dialer{Timeout: DIAL_TIMEOUT, KeepAlive: KEEP_ALIVE}
+  conn, err := dialer.Dial("tcp", address)
+  if err != nil {
+    return nil, err
+  }
+  if tcp, ok := conn.(*net.TCPConn); ok {
+    // commands are short, don't wait to fill segments
+    tcp.SetNoDelay(true)
+  }
+  return &TCP{conn}, nil
+}
@@ -0,0 +1,59 @@
+package Transport
+
+import (
+  "bytes"
+  "io"
+  "net"
+  "strings"
+  "testing"
+  "time"
+)
+
+func TestTCPLoopback(t *testing.T) {
+  listener, err := net.Listen("tcp", "127.0.0.1:0")
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer listener.Close()
+  // serial server echoes everything back
+  go func() {
+    conn, err := listener.Accept()
+    if err != nil {
+      return
+    }
+    defer conn.Close()
+    io.Copy(conn, conn)
+  }()
+  transport, err := Dial("tcp://" + listener.Addr().String())
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer transport.Close()
+  if _, ok := transport.(*TCP); !ok {
+    t.Fatalf("%T dialed", transport)
+  }
+  out := []byte{0xC1, 0x00, 0x09, IAC}
+  if _, err := transport.Write(out); err != nil {
+    t.Fatal(err)
+  }
+  in := make([]byte, len(out))
+  transport.(*TCP).SetReadDeadline(time.Now().Add(time.Second))
+  if _, err := io.ReadFull(transport, in); err != nil {
+    t.Fatal(err)
+  }
+  if !bytes.Equal(in, out) {
+    t.Fatalf("received % X, expected % X", in, out)
+  }
+}
+
+func TestDialErrors(t *testing.T) {
+  for _, device := range []string{"tcp://", "tcp://127.0.0.1", "tcp://127.0.0.1:", "tcp://:4001"} {
+    if transport, err := Dial(device); err == nil {
+      transport.Close()
+      t.Fatalf("%s is dialed", device)
+    }
+  }
+  if _, err := Dial("foo://127.0.0.1:4001"); err == nil || !strings.Contains(err.Error(), "Unknown transport scheme") {
+    t.Fatalf("error %v, expected unknown scheme", err)
+  }
+}
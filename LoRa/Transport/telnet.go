@@ -0,0 +1,106 @@
+package Transport
+
+var (
+  IAC byte                            = 0xFF
+  DONT byte                           = 0xFE
+  DO byte                             = 0xFD
+  WONT byte                           = 0xFC
+  WILL byte                           = 0xFB
+  SB byte                             = 0xFA
+  SE byte                             = 0xF0
+
+  OPTION_BINARY byte                  = 0x00
+  OPTION_SUPPRESS_GO_AHEAD byte       = 0x03
+  OPTION_COM_PORT byte                = 0x2C
+)
+
+const (
+  telnetData = iota
+  telnetIAC
+  telnetOption
+  telnetSB
+  telnetSBIAC
+)
+
+// telnet splits Telnet stream into data and commands
+type telnet struct {
+  state int
+  command byte
+  sb []byte
+  option func(command, option byte)   // WILL, WONT, DO, DONT received
+  subnegotiation func(data []byte)     // SB ... SE received, IAC unescaped
+}
+
+// decode appends data bytes of in to out and dispatches commands
+func (t *telnet) decode(in, out []byte) []byte {
+  for _, b := range in {
+    switch t.state {
+    case telnetData:
+      if b == IAC {
+        t.state = telnetIAC
+      } else {
+        out = append(out, b)
+      }
+    case telnetIAC:
+      switch b {
+      case IAC:
+        out = append(out, b)
+        t.state = telnetData
+      case WILL, WONT, DO, DONT:
+        t.command = b
+        t.state = telnetOption
+      case SB:
+        t.sb = t.sb[:0]
+        t.state = telnetSB
+      default:
+        // NOP, GA and other commands carry nothing useful for a serial link
+        t.state = telnetData
+      }
+    case telnetOption:
+      if t.option != nil {
+        t.option(t.command, b)
+      }
+      t.state = telnetData
+    case telnetSB:
+      if b == IAC {
+        t.state = telnetSBIAC
+      } else {
+        t.sb = append(t.sb, b)
+      }
+    case telnetSBIAC:
+      switch b {
+      case IAC:
+        t.sb = append(t.sb, b)
+        t.state = telnetSB
+      case SE:
+        if t.subnegotiation != nil {
+          t.subnegotiation(append([]byte{}, t.sb...))
+        }
+        t.state = telnetData
+      default:
+        // broken subnegotiation, drop it
+        t.state = telnetData
+      }
+    }
+  }
+  return out
+}
+
+// escape doubles IAC bytes of data
+func escape(data []byte) []byte {
+  out := make([]byte, 0, len(data))
+  for _, b := range data {
+    out = append(out, b)
+    if b == IAC {
+      out = append(out, IAC)
+    }
+  }
+  return out
+}
+
+// subnegotiation frames escaped option data as IAC SB option ... IAC SE
+func subnegotiation(option byte, data ...byte) []byte {
+  out := []byte{IAC, SB, option}
+  out = append(out, escape(data)...)
+  return append(out, IAC, SE)
+}
@@ -0,0 +1,47 @@
+package LoRa
+
+import (
+  "errors"
+  "time"
+)
+
+const (
+  AUX_POLL_INTERVAL = time.Millisecond
+  AUX_SETTLE_TIME = 2 * time.Millisecond    // datasheet delay after AUX rising edge
+  AUX_TIMEOUT = time.Second
+)
+
+var (
+  Busy = errors.New("Device is busy (AUX is low)")
+)
+
+// AuxMonitor watches module AUX pin which is low while module is busy:
+// self-check, mode switching, transmitting buffered data
+type AuxMonitor interface {
+  Ready() (bool, error)
+}
+
+// WaitReady polls aux until module is ready or timeout expires; it returns
+// at once if aux is nil
+func WaitReady(aux AuxMonitor, timeout time.Duration) error {
+  if aux == nil {
+    return nil
+  }
+  deadline := time.Now().Add(timeout)
+  for waited := false; ; waited = true {
+    ready, err := aux.Ready()
+    if err != nil {
+      return err
+    }
+    if ready {
+      if waited {
+        time.Sleep(AUX_SETTLE_TIME)
+      }
+      return nil
+    }
+    if time.Now().After(deadline) {
+      return Busy
+    }
+    time.Sleep(AUX_POLL_INTERVAL)
+  }
+}
@@ -0,0 +1,109 @@
+package LoRa_test
+
+import (
+  "bytes"
+  "e22config/LoRa"
+  "e22config/LoRa/GPIO"
+  "errors"
+  "io"
+  "testing"
+  "time"
+)
+
+// busyTransport records writes and keeps AUX low for busyPolls after each
+// one as module does while transmitting
+type busyTransport struct {
+  aux *GPIO.FakeAux
+  busyPolls int
+  writes [][]byte
+  closed chan struct{}
+}
+
+func newBusyTransport(aux *GPIO.FakeAux, busyPolls int) *busyTransport {
+  return &busyTransport{aux: aux, busyPolls: busyPolls, closed: make(chan struct{})}
+}
+
+func (t *busyTransport) Read(p []byte) (int, error) {
+  <-t.closed
+  return 0, io.EOF
+}
+
+func (t *busyTransport) Write(p []byte) (int, error) {
+  t.writes = append(t.writes, append([]byte{}, p...))
+  t.aux.BusyPolls = t.busyPolls
+  return len(p), nil
+}
+
+func (t *busyTransport) Close() error {
+  close(t.closed)
+  return nil
+}
+
+func TestWaitReady(t *testing.T) {
+  aux := &GPIO.FakeAux{BusyPolls: 3}
+  if err := LoRa.WaitReady(aux, time.Second); err != nil {
+    t.Fatal(err)
+  }
+  if aux.Polls != 4 {
+    t.Fatalf("%d polls, expected 4", aux.Polls)
+  }
+  if err := LoRa.WaitReady(nil, 0); err != nil {
+    t.Fatal(err)
+  }
+}
+
+func TestWaitReadyTimeout(t *testing.T) {
+  aux := &GPIO.FakeAux{BusyPolls: 1 << 30}
+  start := time.Now()
+  if err := LoRa.WaitReady(aux, 20 * time.Millisecond); err != LoRa.Busy {
+    t.Fatalf("error %v, expected Busy", err)
+  }
+  if time.Since(start) < 20 * time.Millisecond {
+    t.Fatal("timeout isn't waited")
+  }
+}
+
+func TestWaitReadyError(t *testing.T) {
+  failure := errors.New("GPIO failure")
+  if err := LoRa.WaitReady(&GPIO.FakeAux{Err: failure}, time.Second); err != failure {
+    t.Fatalf("error %v, expected %v", err, failure)
+  }
+}
+
+func TestTransmitWaitsBetweenChunks(t *testing.T) {
+  aux := &GPIO.FakeAux{}
+  transport := newBusyTransport(aux, 2)
+  port := LoRa.Port{Aux: aux}
+  if err := port.Open(transport); err != nil {
+    t.Fatal(err)
+  }
+  defer port.Close()
+  data := []byte("0123456789")
+  if err := port.Transmit(data, 4); err != nil {
+    t.Fatal(err)
+  }
+  if len(transport.writes) != 3 || !bytes.Equal(bytes.Join(transport.writes, nil), data) {
+    t.Fatalf("writes %q", transport.writes)
+  }
+  // the first chunk is sent at once, the others after 2 busy polls
+  if aux.Polls != 1 + 3 + 3 {
+    t.Fatalf("%d polls, expected 7", aux.Polls)
+  }
+}
+
+func TestTransmitAuxError(t *testing.T) {
+  failure := errors.New("GPIO failure")
+  aux := &GPIO.FakeAux{Err: failure}
+  transport := newBusyTransport(aux, 0)
+  port := LoRa.Port{Aux: aux}
+  if err := port.Open(transport); err != nil {
+    t.Fatal(err)
+  }
+  defer port.Close()
+  if err := port.Transmit([]byte("0123"), 2); err != failure {
+    t.Fatalf("error %v, expected %v", err, failure)
+  }
+  if len(transport.writes) != 0 {
+    t.Fatalf("writes %q without AUX", transport.writes)
+  }
+}
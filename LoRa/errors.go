@@ -0,0 +1,46 @@
+package LoRa
+
+import (
+  "fmt"
+)
+
+// ConfigError is returned when a register field can't be encoded or decoded
+type ConfigError struct {
+  Field string
+  Value interface{}
+}
+
+func (e *ConfigError) Error() string {
+  return fmt.Sprintf("Unknown %s value: %v", e.Field, e.Value)
+}
+
+// ShortFrameError is returned when response ends before declared length
+type ShortFrameError struct {
+  Expected int
+  Received int
+}
+
+func (e *ShortFrameError) Error() string {
+  return fmt.Sprintf("Short response: %d of %d bytes received", e.Received, e.Expected)
+}
+
+// FrameError is returned when response header doesn't match the command
+type FrameError struct {
+  Field string
+  Expected int
+  Received int
+}
+
+func (e *FrameError) Error() string {
+  return fmt.Sprintf("Garbled response: %s is 0x%02X, expected 0x%02X", e.Field, e.Received, e.Expected)
+}
+
+// PayloadError is returned when data doesn't fit into a sub-packet
+type PayloadError struct {
+  Length int
+  Max int
+}
+
+func (e *PayloadError) Error() string {
+  return fmt.Sprintf("Payload too long: %d bytes, sub-packet is %d bytes", e.Length, e.Max)
+}
@@ -0,0 +1,33 @@
+package LoRa
+
+import (
+  "fmt"
+  "math"
+)
+
+// FrequencyPlan maps channel index to carrier frequency
+type FrequencyPlan struct {
+  Band            int       // nominal band, MHz
+  Base            float64   // frequency of channel 0, MHz
+  Step            float64   // channel spacing, MHz
+  Channels        int
+  DefaultChannel  byte
+}
+
+// Frequency returns carrier frequency of the channel in MHz
+func (p FrequencyPlan) Frequency(channel byte) (float64, error) {
+  if int(channel) >= p.Channels {
+    return 0, &ConfigError{Field: "Channel", Value: channel}
+  }
+  return p.Base + float64(channel) * p.Step, nil
+}
+
+// Channel returns index of the channel nearest to the frequency in MHz, so
+// typed frequencies like 433.1 or 868.1250 are accepted
+func (p FrequencyPlan) Channel(frequency float64) (byte, error) {
+  channel := math.Round((frequency - p.Base) / p.Step)
+  if math.IsNaN(channel) || channel < 0 || int(channel) >= p.Channels {
+    return 0, &ConfigError{Field: "Frequency", Value: fmt.Sprintf("%g MHz", frequency)}
+  }
+  return byte(channel), nil
+}
@@ -0,0 +1,53 @@
+package LoRa
+
+// Mode is operating mode selected by M0/M1 pins
+type Mode int
+
+const (
+  MODE_NORMAL Mode = iota     // M1=0, M0=0
+  MODE_WOR                    // M1=0, M0=1
+  MODE_CONFIGURATION          // M1=1, M0=0 (power saving for E31/E32)
+  MODE_SLEEP                  // M1=1, M0=1 (configuration for E31/E32)
+)
+
+// Pins returns M0 and M1 levels of the mode
+func (m Mode) Pins() (m0, m1 bool) {
+  return m & 1 != 0, m & 2 != 0
+}
+
+func (m Mode) String() string {
+  switch m {
+  case MODE_NORMAL:
+    return "Normal"
+  case MODE_WOR:
+    return "WOR"
+  case MODE_CONFIGURATION:
+    return "Configuration"
+  case MODE_SLEEP:
+    return "Sleep"
+  }
+  return "Unknown"
+}
+
+// ModeController switches module modes with M0/M1 pins
+type ModeController interface {
+  Mode() Mode
+  SetMode(mode Mode) error
+}
+
+// WithMode runs f with module switched to mode and restores previous mode
+// afterwards; f is just called if pins are nil (set by jumpers)
+func WithMode(pins ModeController, mode Mode, f func() error) error {
+  if pins == nil || pins.Mode() == mode {
+    return f()
+  }
+  previous := pins.Mode()
+  if err := pins.SetMode(mode); err != nil {
+    return err
+  }
+  err := f()
+  if restore := pins.SetMode(previous); err == nil {
+    err = restore
+  }
+  return err
+}
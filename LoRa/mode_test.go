@@ -0,0 +1,67 @@
+package LoRa_test
+
+import (
+  "e22config/LoRa"
+  "e22config/LoRa/GPIO"
+  "errors"
+  "testing"
+)
+
+func TestWithModeRestores(t *testing.T) {
+  pins := &GPIO.FakeModePins{Current: LoRa.MODE_NORMAL}
+  var during LoRa.Mode
+  err := LoRa.WithMode(pins, LoRa.MODE_CONFIGURATION, func() error {
+    during = pins.Mode()
+    return nil
+  })
+  if err != nil {
+    t.Fatal(err)
+  }
+  if during != LoRa.MODE_CONFIGURATION {
+    t.Fatalf("f called in %s mode", during)
+  }
+  if pins.Mode() != LoRa.MODE_NORMAL {
+    t.Fatalf("%s mode left", pins.Mode())
+  }
+}
+
+func TestWithModeRestoresOnError(t *testing.T) {
+  pins := &GPIO.FakeModePins{Current: LoRa.MODE_WOR}
+  failure := errors.New("failure")
+  err := LoRa.WithMode(pins, LoRa.MODE_SLEEP, func() error {
+    return failure
+  })
+  if err != failure {
+    t.Fatalf("error %v, expected %v", err, failure)
+  }
+  expected := []LoRa.Mode{LoRa.MODE_SLEEP, LoRa.MODE_WOR}
+  if len(pins.History) != len(expected) || pins.History[0] != expected[0] || pins.History[1] != expected[1] {
+    t.Fatalf("modes %v, expected %v", pins.History, expected)
+  }
+}
+
+func TestWithModeSwitchFailure(t *testing.T) {
+  failure := errors.New("GPIO failure")
+  pins := &GPIO.FakeModePins{Current: LoRa.MODE_NORMAL, Err: failure}
+  called := false
+  err := LoRa.WithMode(pins, LoRa.MODE_CONFIGURATION, func() error {
+    called = true
+    return nil
+  })
+  if err != failure || called {
+    t.Fatalf("error %v, f called: %v", err, called)
+  }
+}
+
+func TestWithModeKeepsCurrent(t *testing.T) {
+  pins := &GPIO.FakeModePins{Current: LoRa.MODE_CONFIGURATION}
+  if err := LoRa.WithMode(pins, LoRa.MODE_CONFIGURATION, func() error { return nil }); err != nil {
+    t.Fatal(err)
+  }
+  if len(pins.History) != 0 {
+    t.Fatalf("modes %v switched", pins.History)
+  }
+  if err := LoRa.WithMode(nil, LoRa.MODE_CONFIGURATION, func() error { return nil }); err != nil {
+    t.Fatal(err)
+  }
+}
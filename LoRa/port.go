@@ -0,0 +1,243 @@
+package LoRa
+
+import (
+  "context"
+  "errors"
+  "io"
+  "sync"
+  "time"
+)
+
+var (
+  NoResponse = errors.New("No response from device")
+  NotOpen = errors.New("Device is not open")
+  NoData = errors.New("No data received")
+)
+
+// Transport is a link to the module UART: serial port, socket, etc.
+type Transport interface {
+  io.ReadWriteCloser
+}
+
+// Port serializes command/response exchanges over a Transport; it's shared
+// by family drivers
+type Port struct {
+  IsOpen bool
+  Aux AuxMonitor    // AUX pin, module readiness isn't checked if nil
+  transport Transport
+  rx chan []byte
+  pending []byte    // received bytes beyond the last packet
+  done chan struct{}
+  mutex sync.Mutex
+}
+
+// Open attaches port to the transport; transport is owned by port until Close
+func (p *Port) Open(transport Transport) error {
+  p.mutex.Lock()
+  defer p.mutex.Unlock()
+  if p.IsOpen {
+    return errors.New("Device is already open")
+  }
+  p.transport = transport
+  p.rx = make(chan []byte, 64)
+  p.pending = nil
+  p.done = make(chan struct{})
+  go pump(transport, p.rx, p.done)
+  p.IsOpen = true
+  return nil
+}
+
+// Close detaches port and closes the transport
+func (p *Port) Close() error {
+  p.mutex.Lock()
+  defer p.mutex.Unlock()
+  if !p.IsOpen {
+    return nil
+  }
+  p.IsOpen = false
+  close(p.done)
+  return p.transport.Close()
+}
+
+// pump moves incoming bytes to rx until transport fails or port is closed
+func pump(transport Transport, rx chan<- []byte, done <-chan struct{}) {
+  defer close(rx)
+  for {
+    buffer := make([]byte, 256)
+    n, err := transport.Read(buffer)
+    if n > 0 {
+      select {
+      case rx <- buffer[:n]:
+      case <-done:
+        return
+      }
+    }
+    if err != nil {
+      return
+    }
+  }
+}
+
+// Send writes concatenated data without waiting for response
+func (p *Port) Send(data ...[]byte) error {
+  p.mutex.Lock()
+  defer p.mutex.Unlock()
+  if err := WaitReady(p.Aux, AUX_TIMEOUT); err != nil {
+    return err
+  }
+  return p.send(data...)
+}
+
+// Transmit writes data by chunks waiting for module buffer to be flushed
+// (AUX high) before each one; data is written at once without AUX monitor
+func (p *Port) Transmit(data []byte, chunk int) error {
+  p.mutex.Lock()
+  defer p.mutex.Unlock()
+  if p.Aux == nil || chunk <= 0 {
+    return p.send(data)
+  }
+  for len(data) > 0 {
+    n := chunk
+    if n > len(data) {
+      n = len(data)
+    }
+    if err := WaitReady(p.Aux, AUX_TIMEOUT); err != nil {
+      return err
+    }
+    if err := p.send(data[:n]); err != nil {
+      return err
+    }
+    data = data[n:]
+    // give module time to pull AUX low
+    time.Sleep(AUX_POLL_INTERVAL)
+  }
+  return nil
+}
+
+// WaitReady waits for the module to finish self-check or transmission
+func (p *Port) WaitReady(timeout time.Duration) error {
+  return WaitReady(p.Aux, timeout)
+}
+
+func (p *Port) send(data ...[]byte) error {
+  if !p.IsOpen {
+    return NotOpen
+  }
+  cmd := []byte{}
+  for _, item := range data {
+    cmd = append(cmd, item...)
+  }
+  _, err := p.transport.Write(cmd)
+  return err
+}
+
+// Command sends concatenated data and collects response until complete
+// reports a full frame; partial response is returned as is when ctx deadline
+// expires. AUX is waited for until the same deadline, AUX_TIMEOUT if ctx has
+// none
+func (p *Port) Command(ctx context.Context, complete func([]byte) bool, data ...[]byte) ([]byte, error) {
+  p.mutex.Lock()
+  defer p.mutex.Unlock()
+  if !p.IsOpen {
+    return nil, NotOpen
+  }
+  timeout := AUX_TIMEOUT
+  if deadline, ok := ctx.Deadline(); ok {
+    timeout = time.Until(deadline)
+  }
+  if err := WaitReady(p.Aux, timeout); err != nil {
+    return nil, err
+  }
+  p.pending = nil
+  for drained := false; !drained; {
+    select {
+    case _, ok := <-p.rx:
+      drained = !ok
+    default:
+      drained = true
+    }
+  }
+  if err := p.send(data...); err != nil {
+    return nil, err
+  }
+
+  response := []byte{}
+  for !complete(response) {
+    select {
+    case chunk, ok := <-p.rx:
+      if !ok {
+        return response, io.ErrUnexpectedEOF
+      }
+      response = append(response, chunk...)
+    case <-ctx.Done():
+      if ctx.Err() != context.DeadlineExceeded {
+        return response, ctx.Err()
+      }
+      if len(response) == 0 {
+        return response, NoResponse
+      }
+      return response, nil
+    }
+  }
+  return response, nil
+}
+
+// Receive waits for incoming data and collects it until the line is idle
+// for gap or max bytes are received; timeout <= 0 waits forever. It must not
+// be mixed with Command which discards unread data
+func (p *Port) Receive(timeout, gap time.Duration, max int) ([]byte, error) {
+  // port may be closed meanwhile, the lock isn't held while waiting
+  p.mutex.Lock()
+  if !p.IsOpen {
+    p.mutex.Unlock()
+    return nil, NotOpen
+  }
+  rx := p.rx
+  packet := p.pending
+  p.pending = nil
+  p.mutex.Unlock()
+  var expired <-chan time.Time
+  if timeout > 0 {
+    timer := time.NewTimer(timeout)
+    defer timer.Stop()
+    expired = timer.C
+  }
+  for len(packet) == 0 {
+    select {
+    case chunk, ok := <-rx:
+      if !ok {
+        return nil, io.EOF
+      }
+      packet = chunk
+    case <-expired:
+      return nil, NoData
+    }
+  }
+  idle := time.NewTimer(gap)
+  defer idle.Stop()
+  for len(packet) < max {
+    select {
+    case chunk, ok := <-rx:
+      if !ok {
+        return packet, nil
+      }
+      packet = append(packet, chunk...)
+      if !idle.Stop() {
+        select {
+        case <-idle.C:
+        default:
+        }
+      }
+      idle.Reset(gap)
+    case <-idle.C:
+      return packet, nil
+    }
+  }
+  if len(packet) > max {
+    p.mutex.Lock()
+    p.pending = append([]byte{}, packet[max:]...)
+    p.mutex.Unlock()
+    packet = packet[:max]
+  }
+  return packet, nil
+}
@@ -0,0 +1,153 @@
+package LoRa_test
+
+import (
+  "bytes"
+  "context"
+  "e22config/LoRa"
+  "io"
+  "net"
+  "testing"
+  "time"
+)
+
+func TestReceiveKeepsPending(t *testing.T) {
+  host, module := net.Pipe()
+  defer module.Close()
+  port := LoRa.Port{}
+  if err := port.Open(host); err != nil {
+    t.Fatal(err)
+  }
+  defer port.Close()
+  go module.Write([]byte("0123456789"))
+  first, err := port.Receive(time.Second, 20 * time.Millisecond, 4)
+  if err != nil {
+    t.Fatal(err)
+  }
+  second, err := port.Receive(time.Second, 20 * time.Millisecond, 16)
+  if err != nil {
+    t.Fatal(err)
+  }
+  if !bytes.Equal(first, []byte("0123")) || !bytes.Equal(second, []byte("456789")) {
+    t.Fatalf("received %q, %q", first, second)
+  }
+  if _, err := port.Receive(10 * time.Millisecond, time.Millisecond, 16); err != LoRa.NoData {
+    t.Fatalf("error %v, expected NoData", err)
+  }
+}
+
+func TestReceiveWhileClosing(t *testing.T) {
+  host, module := net.Pipe()
+  defer module.Close()
+  port := LoRa.Port{}
+  if err := port.Open(host); err != nil {
+    t.Fatal(err)
+  }
+  result := make(chan error)
+  go func() {
+    _, err := port.Receive(0, time.Millisecond, 16)
+    result <- err
+  }()
+  time.Sleep(10 * time.Millisecond)
+  if err := port.Close(); err != nil {
+    t.Fatal(err)
+  }
+  select {
+  case err := <-result:
+    if err != io.EOF && err != LoRa.NotOpen {
+      t.Fatalf("error %v, expected EOF", err)
+    }
+  case <-time.After(time.Second):
+    t.Fatal("Receive isn't unblocked by Close")
+  }
+  if _, err := port.Receive(0, time.Millisecond, 16); err != LoRa.NotOpen {
+    t.Fatalf("error %v, expected NotOpen", err)
+  }
+}
+
+func TestCommandReturnsWhenComplete(t *testing.T) {
+  host, module := net.Pipe()
+  defer module.Close()
+  port := LoRa.Port{}
+  if err := port.Open(host); err != nil {
+    t.Fatal(err)
+  }
+  defer port.Close()
+  go func() {
+    buffer := make([]byte, 16)
+    module.Read(buffer)
+    module.Write([]byte{0xC1, 0x00})
+    module.Write([]byte{0x01, 0x12})
+  }()
+  complete := func(response []byte) bool {
+    return len(response) >= 4
+  }
+  start := time.Now()
+  ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+  defer cancel()
+  response, err := port.Command(ctx, complete, []byte{0xC1, 0x00, 0x01})
+  if err != nil {
+    t.Fatal(err)
+  }
+  if !bytes.Equal(response, []byte{0xC1, 0x00, 0x01, 0x12}) {
+    t.Fatalf("response % X", response)
+  }
+  if time.Since(start) > 500 * time.Millisecond {
+    t.Fatal("timeout is waited for complete response")
+  }
+}
+
+func TestCommandTimeout(t *testing.T) {
+  host, module := net.Pipe()
+  defer module.Close()
+  port := LoRa.Port{}
+  if err := port.Open(host); err != nil {
+    t.Fatal(err)
+  }
+  defer port.Close()
+  go func() {
+    buffer := make([]byte, 16)
+    module.Read(buffer)
+    module.Write([]byte{0xC1})
+    module.Read(buffer)
+  }()
+  complete := func(response []byte) bool {
+    return len(response) >= 4
+  }
+  ctx, cancel := context.WithTimeout(context.Background(), 20 * time.Millisecond)
+  defer cancel()
+  response, err := port.Command(ctx, complete, []byte{0xC1, 0x00, 0x01})
+  if err != nil || !bytes.Equal(response, []byte{0xC1}) {
+    t.Fatalf("response % X, error %v", response, err)
+  }
+  ctx, cancel = context.WithTimeout(context.Background(), 20 * time.Millisecond)
+  defer cancel()
+  response, err = port.Command(ctx, complete, []byte{0xC1, 0x00, 0x01})
+  if err != LoRa.NoResponse || len(response) != 0 {
+    t.Fatalf("response % X, error %v, expected NoResponse", response, err)
+  }
+}
+
+func TestCommandCanceled(t *testing.T) {
+  host, module := net.Pipe()
+  defer module.Close()
+  port := LoRa.Port{}
+  if err := port.Open(host); err != nil {
+    t.Fatal(err)
+  }
+  defer port.Close()
+  go func() {
+    buffer := make([]byte, 16)
+    module.Read(buffer)
+  }()
+  ctx, cancel := context.WithCancel(context.Background())
+  go func() {
+    time.Sleep(20 * time.Millisecond)
+    cancel()
+  }()
+  complete := func(response []byte) bool {
+    return len(response) >= 4
+  }
+  if _, err := port.Command(ctx, complete, []byte{0xC1, 0x00, 0x01}); err != context.Canceled {
+    t.Fatalf("error %v, expected context.Canceled", err)
+  }
+}
@@ -0,0 +1,78 @@
+package LoRa
+
+import (
+  "context"
+  "errors"
+  "log"
+  "time"
+)
+
+// RetryPolicy repeats failed module commands; zero value makes a single
+// attempt
+type RetryPolicy struct {
+  Attempts int             // total number of attempts, 1 if zero
+  Backoff time.Duration    // delay before the second attempt, doubled for each next one
+  MaxBackoff time.Duration // backoff limit, unlimited if zero
+  Errors []error           // retryable errors in addition to DefaultRetryable ones
+  Logf func(format string, args ...interface{})  // attempt log, log.Printf if nil
+}
+
+// DefaultRetryable reports if err is caused by lost or garbled response;
+// rejected commands and invalid settings are not retried by default
+func DefaultRetryable(err error) bool {
+  var short *ShortFrameError
+  var frame *FrameError
+  return errors.Is(err, NoResponse) || errors.Is(err, Busy) ||
+    errors.As(err, &short) || errors.As(err, &frame)
+}
+
+// Retryable reports if err is worth another attempt
+func (r *RetryPolicy) Retryable(err error) bool {
+  if DefaultRetryable(err) {
+    return true
+  }
+  for _, item := range r.Errors {
+    if errors.Is(err, item) {
+      return true
+    }
+  }
+  return false
+}
+
+// Do calls f until it succeeds, fails with not retryable error or attempts
+// are exhausted; the last error is returned. Backoff is interrupted and
+// ctx error is returned when ctx is done
+func (r *RetryPolicy) Do(ctx context.Context, name string, f func() error) error {
+  attempts := r.Attempts
+  if attempts < 1 {
+    attempts = 1
+  }
+  logf := r.Logf
+  if logf == nil {
+    logf = log.Printf
+  }
+  backoff := r.Backoff
+  var err error
+  for attempt := 1; attempt <= attempts; attempt++ {
+    if err = f(); err == nil {
+      return nil
+    }
+    logf("%s attempt %d of %d FAILED: %v", name, attempt, attempts, err)
+    if attempt == attempts || !r.Retryable(err) {
+      break
+    }
+    logf("%s retry in %v", name, backoff)
+    timer := time.NewTimer(backoff)
+    select {
+    case <-ctx.Done():
+      timer.Stop()
+      return ctx.Err()
+    case <-timer.C:
+    }
+    backoff *= 2
+    if r.MaxBackoff > 0 && backoff > r.MaxBackoff {
+      backoff = r.MaxBackoff
+    }
+  }
+  return err
+}
@@ -0,0 +1,121 @@
+package LoRa_test
+
+import (
+  "context"
+  "e22config/LoRa"
+  "e22config/LoRa/E22"
+  "errors"
+  "strings"
+  "testing"
+  "time"
+)
+
+// failing returns f failing with errs in turn and counter of its calls
+func failing(errs ...error) (func() error, *int) {
+  calls := 0
+  return func() error {
+    calls++
+    if calls > len(errs) {
+      return nil
+    }
+    return errs[calls - 1]
+  }, &calls
+}
+
+// logged collects lines of the attempt log and backoffs announced in it
+type logged struct {
+  lines []string
+  backoffs []time.Duration
+}
+
+func (l *logged) Logf(format string, args ...interface{}) {
+  l.lines = append(l.lines, format)
+  if strings.HasSuffix(format, "retry in %v") {
+    l.backoffs = append(l.backoffs, args[len(args) - 1].(time.Duration))
+  }
+}
+
+func TestRetryAttempts(t *testing.T) {
+  log := &logged{}
+  r := LoRa.RetryPolicy{Attempts: 3, Logf: log.Logf}
+  f, calls := failing(LoRa.NoResponse, LoRa.NoResponse, LoRa.NoResponse, LoRa.NoResponse)
+  if err := r.Do(context.Background(), "test", f); err != LoRa.NoResponse {
+    t.Fatalf("error %v, expected NoResponse", err)
+  }
+  if *calls != 3 {
+    t.Fatalf("%d attempts, expected 3", *calls)
+  }
+  f, calls = failing(LoRa.NoResponse)
+  if err := r.Do(context.Background(), "test", f); err != nil {
+    t.Fatal(err)
+  }
+  if *calls != 2 {
+    t.Fatalf("%d attempts, expected 2", *calls)
+  }
+  // zero value makes a single attempt
+  f, calls = failing(LoRa.NoResponse)
+  zero := LoRa.RetryPolicy{Logf: log.Logf}
+  if err := zero.Do(context.Background(), "test", f); err != LoRa.NoResponse || *calls != 1 {
+    t.Fatalf("%d attempts, error %v", *calls, err)
+  }
+}
+
+func TestRetryBackoff(t *testing.T) {
+  log := &logged{}
+  r := LoRa.RetryPolicy{Attempts: 6, Backoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond, Logf: log.Logf}
+  f, _ := failing(LoRa.Busy, LoRa.Busy, LoRa.Busy, LoRa.Busy, LoRa.Busy, LoRa.Busy)
+  r.Do(context.Background(), "test", f)
+  expected := []time.Duration{1, 2, 4, 5, 5}
+  if len(log.backoffs) != len(expected) {
+    t.Fatalf("backoffs %v", log.backoffs)
+  }
+  for i := range expected {
+    if log.backoffs[i] != expected[i] * time.Millisecond {
+      t.Fatalf("backoffs %v, expected %v ms", log.backoffs, expected)
+    }
+  }
+}
+
+func TestRetryStopsOnRejection(t *testing.T) {
+  log := &logged{}
+  r := LoRa.RetryPolicy{Attempts: 5, Logf: log.Logf}
+  rejected := &LoRa.ConfigError{Field: "Channel", Value: 200}
+  f, calls := failing(rejected, rejected)
+  if err := r.Do(context.Background(), "test", f); err != rejected {
+    t.Fatalf("error %v, expected ConfigError", err)
+  }
+  if *calls != 1 || len(log.backoffs) != 0 {
+    t.Fatalf("%d attempts, %d retries", *calls, len(log.backoffs))
+  }
+  if len(log.lines) != 1 {
+    t.Fatalf("%d lines logged, expected 1", len(log.lines))
+  }
+}
+
+func TestRetryExtraErrors(t *testing.T) {
+  log := &logged{}
+  r := LoRa.RetryPolicy{Attempts: 3, Logf: log.Logf}
+  f, calls := failing(E22.WrongFormat)
+  if err := r.Do(context.Background(), "test", f); !errors.Is(err, E22.WrongFormat) || *calls != 1 {
+    t.Fatalf("%d attempts, error %v", *calls, err)
+  }
+  r.Errors = []error{E22.WrongFormat}
+  f, calls = failing(E22.WrongFormat, E22.WrongFormat)
+  if err := r.Do(context.Background(), "test", f); err != nil || *calls != 3 {
+    t.Fatalf("%d attempts, error %v", *calls, err)
+  }
+}
+
+func TestRetryCanceled(t *testing.T) {
+  log := &logged{}
+  r := LoRa.RetryPolicy{Attempts: 3, Backoff: time.Hour, Logf: log.Logf}
+  ctx, cancel := context.WithCancel(context.Background())
+  go func() {
+    time.Sleep(10 * time.Millisecond)
+    cancel()
+  }()
+  f, calls := failing(LoRa.NoResponse, LoRa.NoResponse)
+  if err := r.Do(ctx, "test", f); err != context.Canceled || *calls != 1 {
+    t.Fatalf("%d attempts, error %v", *calls, err)
+  }
+}
@@ -0,0 +1,180 @@
+package LoRa
+
+import (
+  "io"
+  "net"
+  "os"
+  "sync"
+  "sync/atomic"
+  "time"
+)
+
+const (
+  DEADLINE_POLL = 100 * time.Millisecond  // deadline and close check interval of blocked reads
+  STREAM_NETWORK = "lora"
+)
+
+// StreamAddr names the end of transparent link, it implements net.Addr
+type StreamAddr struct {
+  Name string
+}
+
+func (a *StreamAddr) Network() string {
+  return STREAM_NETWORK
+}
+
+func (a *StreamAddr) String() string {
+  return a.Name
+}
+
+// Stream implements net.Conn over module in transparent mode: bytes written
+// are transmitted to every module with the same address and channel
+type Stream struct {
+  port *Port
+  chunk int
+  buffer []byte       // received bytes not read yet
+  reading sync.Mutex
+  mutex sync.Mutex
+  readDeadline time.Time
+  writeDeadline time.Time
+  closing chan struct{}
+  closed bool
+  bytesRead uint64
+  bytesWritten uint64
+}
+
+// NewStream wraps open port; writes are split by chunk bytes and throttled
+// by AUX if it's monitored. Port must not be used for commands meanwhile
+func NewStream(port *Port, chunk int) (*Stream, error) {
+  port.mutex.Lock()
+  defer port.mutex.Unlock()
+  if !port.IsOpen {
+    return nil, NotOpen
+  }
+  s := &Stream{port: port, chunk: chunk, buffer: port.pending, closing: make(chan struct{})}
+  port.pending = nil
+  return s, nil
+}
+
+func (s *Stream) opError(op string, err error) error {
+  return &net.OpError{Op: op, Net: STREAM_NETWORK, Source: s.LocalAddr(), Addr: s.RemoteAddr(), Err: err}
+}
+
+// state returns closed flag and deadline under lock
+func (s *Stream) state(write bool) (bool, time.Time) {
+  s.mutex.Lock()
+  defer s.mutex.Unlock()
+  if write {
+    return s.closed, s.writeDeadline
+  }
+  return s.closed, s.readDeadline
+}
+
+// Read returns received bytes as soon as any are available
+func (s *Stream) Read(b []byte) (int, error) {
+  s.reading.Lock()
+  defer s.reading.Unlock()
+  for len(s.buffer) == 0 {
+    closed, deadline := s.state(false)
+    if closed {
+      return 0, s.opError("read", net.ErrClosed)
+    }
+    wait := DEADLINE_POLL
+    if !deadline.IsZero() {
+      left := time.Until(deadline)
+      if left <= 0 {
+        return 0, s.opError("read", os.ErrDeadlineExceeded)
+      }
+      if left < wait {
+        wait = left
+      }
+    }
+    timer := time.NewTimer(wait)
+    select {
+    case chunk, ok := <-s.port.rx:
+      if !ok {
+        timer.Stop()
+        return 0, io.EOF
+      }
+      s.buffer = chunk
+    case <-s.closing:
+    case <-timer.C:
+    }
+    timer.Stop()
+  }
+  n := copy(b, s.buffer)
+  s.buffer = s.buffer[n:]
+  atomic.AddUint64(&s.bytesRead, uint64(n))
+  return n, nil
+}
+
+// Write transmits b; deadline is checked before transmission starts
+func (s *Stream) Write(b []byte) (int, error) {
+  closed, deadline := s.state(true)
+  if closed {
+    return 0, s.opError("write", net.ErrClosed)
+  }
+  if !deadline.IsZero() && !time.Now().Before(deadline) {
+    return 0, s.opError("write", os.ErrDeadlineExceeded)
+  }
+  if err := s.port.Transmit(b, s.chunk); err != nil {
+    return 0, s.opError("write", err)
+  }
+  atomic.AddUint64(&s.bytesWritten, uint64(len(b)))
+  return len(b), nil
+}
+
+// Close unblocks pending reads and closes the port
+func (s *Stream) Close() error {
+  s.mutex.Lock()
+  if s.closed {
+    s.mutex.Unlock()
+    return s.opError("close", net.ErrClosed)
+  }
+  s.closed = true
+  close(s.closing)
+  s.mutex.Unlock()
+  return s.port.Close()
+}
+
+// BytesRead returns number of bytes returned by Read
+func (s *Stream) BytesRead() uint64 {
+  return atomic.LoadUint64(&s.bytesRead)
+}
+
+// BytesWritten returns number of bytes transmitted by Write
+func (s *Stream) BytesWritten() uint64 {
+  return atomic.LoadUint64(&s.bytesWritten)
+}
+
+func (s *Stream) LocalAddr() net.Addr {
+  return &StreamAddr{"local"}
+}
+
+func (s *Stream) RemoteAddr() net.Addr {
+  return &StreamAddr{"transparent"}
+}
+
+func (s *Stream) SetDeadline(t time.Time) error {
+  s.mutex.Lock()
+  defer s.mutex.Unlock()
+  s.readDeadline = t
+  s.writeDeadline = t
+  return nil
+}
+
+func (s *Stream) SetReadDeadline(t time.Time) error {
+  s.mutex.Lock()
+  defer s.mutex.Unlock()
+  s.readDeadline = t
+  return nil
+}
+
+func (s *Stream) SetWriteDeadline(t time.Time) error {
+  s.mutex.Lock()
+  defer s.mutex.Unlock()
+  s.writeDeadline = t
+  return nil
+}
+
+var _ net.Conn = (*Stream)(nil)
@@ -0,0 +1,96 @@
+package LoRa_test
+
+import (
+  "e22config/LoRa"
+  "errors"
+  "io"
+  "net"
+  "os"
+  "testing"
+  "time"
+)
+
+// stream opens transparent link over a pipe, module end is returned
+func stream(t *testing.T) (*LoRa.Stream, net.Conn) {
+  host, module := net.Pipe()
+  port := &LoRa.Port{}
+  if err := port.Open(host); err != nil {
+    t.Fatal(err)
+  }
+  s, err := LoRa.NewStream(port, 32)
+  if err != nil {
+    t.Fatal(err)
+  }
+  t.Cleanup(func() {
+    s.Close()
+    module.Close()
+  })
+  return s, module
+}
+
+func TestStreamDeadlines(t *testing.T) {
+  s, _ := stream(t)
+  s.SetReadDeadline(time.Now().Add(20 * time.Millisecond))
+  start := time.Now()
+  if _, err := s.Read(make([]byte, 16)); !errors.Is(err, os.ErrDeadlineExceeded) {
+    t.Fatalf("read error %v, expected deadline exceeded", err)
+  }
+  if time.Since(start) > LoRa.DEADLINE_POLL {
+    t.Fatal("read deadline is overslept")
+  }
+  s.SetWriteDeadline(time.Now().Add(-time.Millisecond))
+  if _, err := s.Write([]byte("late")); !errors.Is(err, os.ErrDeadlineExceeded) {
+    t.Fatalf("write error %v, expected deadline exceeded", err)
+  }
+  if s.BytesWritten() != 0 {
+    t.Fatalf("%d bytes written after deadline", s.BytesWritten())
+  }
+}
+
+func TestStreamCloseUnblocksRead(t *testing.T) {
+  s, _ := stream(t)
+  read := make(chan error)
+  go func() {
+    _, err := s.Read(make([]byte, 16))
+    read <- err
+  }()
+  time.Sleep(10 * time.Millisecond)
+  if err := s.Close(); err != nil {
+    t.Fatal(err)
+  }
+  select {
+  case err := <-read:
+    if !errors.Is(err, net.ErrClosed) && err != io.EOF {
+      t.Fatalf("read error %v, expected net.ErrClosed", err)
+    }
+  case <-time.After(time.Second):
+    t.Fatal("Read isn't unblocked by Close")
+  }
+  if err := s.Close(); !errors.Is(err, net.ErrClosed) {
+    t.Fatalf("close error %v, expected net.ErrClosed", err)
+  }
+}
+
+func TestStreamCounters(t *testing.T) {
+  s, module := stream(t)
+  go module.Write([]byte("hello"))
+  buffer := make([]byte, 3)
+  s.SetReadDeadline(time.Now().Add(time.Second))
+  for total := 0; total < 5; {
+    n, err := s.Read(buffer)
+    if err != nil {
+      t.Fatal(err)
+    }
+    total += n
+  }
+  if s.BytesRead() != 5 {
+    t.Fatalf("%d bytes read, expected 5", s.BytesRead())
+  }
+  go io.ReadFull(module, make([]byte, 3))
+  if _, err := s.Write([]byte("abc")); err != nil {
+    t.Fatal(err)
+  }
+  if s.BytesWritten() != 3 {
+    t.Fatalf("%d bytes written, expected 3", s.BytesWritten())
+  }
+}
@@ -3,11 +3,11 @@ module e22config
 go 1.16
 
 require (
-	fyne.io/fyne/v2 v2.1.0 // indirect
+	fyne.io/fyne/v2 v2.1.0
 	github.com/creack/goselect v0.1.2 // indirect
 	github.com/gotk3/gotk3 v0.6.1 // indirect
-	github.com/schollz/progressbar/v3 v3.8.3 // indirect
-	go.bug.st/serial.v1 v0.0.0-20191202182710-24a6610f0541 // indirect
+	github.com/schollz/progressbar/v3 v3.8.3
+	go.bug.st/serial.v1 v0.0.0-20191202182710-24a6610f0541
 	golang.org/x/crypto v0.0.0-20210915214749-c084706c2272 // indirect
 	golang.org/x/sys v0.0.0-20210915083310-ed5796bab164 // indirect
 )
@@ -1,14 +1,21 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"time"
 	"log"
 	"math/rand"
 	"reflect"
+	"strconv"
 	"strings"
 	"encoding/hex"
+	"e22config/LoRa"
 	"e22config/LoRa/E22"
+	"e22config/LoRa/E32"
+	"e22config/LoRa/GPIO"
+	"e22config/LoRa/Simulator"
+	"e22config/LoRa/Transport"
 	"go.bug.st/serial.v1"
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
@@ -29,7 +36,15 @@ type BTLP struct {
 	NETID    					int `json:"address-num-editable"`
 	UARTRate					string `json:"uart-string-select"`
 	UARTParityBit			string `json:"uart-string-select"`
+	IODrive						string `json:"uart-string-select"`
 	Device						string `json:"main-string-select"`
+	Target						string `json:"main-string-select"`
+	LocalUART					string `json:"main-string-select"`
+	Module						string `json:"main-string-select"`
+	ModePins					string `json:"main-string-editable"`
+	Retries						int `json:"main-num-editable"`
+	RetryBackoff			int `json:"main-num-editable"`
+	RetryRejected			bool `json:"main-bool-check"`
 	WirelessRate			string `json:"wireless-string-select"`
 	SubPacketLength		string `json:"wireless-string-select"`
 	AmbientNoise 			bool `json:"wireless-bool-check"`
@@ -39,10 +54,16 @@ type BTLP struct {
 	TransmissionMode	string `json:"wireless-string-select"`
 	Repeater 					bool `json:"wireless-bool-check"`
 	LBT 							bool `json:"wireless-bool-check"`
+	FEC 							bool `json:"wireless-bool-check"`
 	WOR								string `json:"wor-string-select"`
 	WORCycle					string `json:"wor-string-select"`
 	CryptH 						int `json:"cryptography-num-editable"`
 	CryptL 						int `json:"cryptography-num-editable"`
+	Model							string `json:"product-string-label"`
+	Family						string `json:"product-string-label"`
+	Band							string `json:"product-string-label"`
+	MaxPower					string `json:"product-string-label"`
+	Firmware					string `json:"product-string-label"`
 	PID								string `json:"product-string-label"`
 
 	names 	map[string]string
@@ -54,6 +75,8 @@ type BTLP struct {
 	checks map[string]*widget.Check
 	devices []Device
 	table *widget.Table
+	registers *fyne.Container
+	registerCells []fyne.Disableable  // entries and buttons of the grid
 
 	filePath string
 	Buttons map[string]*widget.Button
@@ -61,7 +84,15 @@ type BTLP struct {
 }
 
 var (
+	AUTODETECT string = "Auto-detect"
+	RETRY_MAX_BACKOFF = 2 * time.Second
+	model LoRa.Model = E22.DEFAULT_MODEL
 	lora E22.Device
+	lora32 E32.Device
+	pins *GPIO.ModePins
+	aux *GPIO.AuxPin
+	link LoRa.Transport
+	capture string
 	boot *BTLP
 	noResponse string = "ERROR: No response"
 	noBootloader string = "ERROR: Bootloader not found"
@@ -87,11 +118,19 @@ func NewBTLP() *BTLP {
 		defaults: make(map[string]string),
 		Buttons: make(map[string]*widget.Button),
 	}
-	b.names["Device"] = "Device"
+	b.names["Device"] = "Device (port, tcp://, rfc2217://host:port, sim://model or replay:///file)"
+	b.names["Target"] = "Target module"
+	b.names["LocalUART"] = "Local module UART for wireless target (rate parity)"
+	b.names["Module"] = "Module model"
+	b.names["ModePins"] = "M0/M1/AUX GPIO (chip:M0,M1[,AUX])"
+	b.names["Retries"] = "Command attempts"
+	b.names["RetryBackoff"] = "Retry backoff (ms)"
+	b.names["RetryRejected"] = "Retry rejected commands (FF FF FF)"
 	b.names["ADDH"] = "High byte (ADDH)"
 	b.names["ADDL"] = "Low byte (ADDL)"
 	b.names["UARTRate"] = "Data rate (bps)"
 	b.names["UARTParityBit"] = "Parity bit"
+	b.names["IODrive"] = "AUX/TXD drive mode (E31/E32)"
 	b.names["NETID"] = "Network (NETID)"
 	b.names["WirelessRate"] = "Data rate (bps)"
 	b.names["SubPacketLength"] = "Sub packet length (bytes)"
@@ -102,33 +141,47 @@ func NewBTLP() *BTLP {
 	b.names["TransmissionMode"] = "Transmission mode"
 	b.names["Repeater"] = "Enable repeater"
 	b.names["LBT"] = "Enable monitor before transmission (LBT)"
+	b.names["FEC"] = "Enable forward error correction (E31/E32)"
 	b.names["WOR"] = "Transceiver mode"
 	b.names["WORCycle"] = "Monitoring interval period (ms)"
 	b.names["CryptH"] = "Key high byte (CRYPT_H)"
 	b.names["CryptL"] = "Key low byte (CRYPT_L)"
-	b.names["PID"] = "PID"
+	b.names["Model"] = "Model"
+	b.names["Family"] = "Model family"
+	b.names["Band"] = "Frequency band (MHz)"
+	b.names["MaxPower"] = "Maximum power (dbm)"
+	b.names["Firmware"] = "Firmware version"
+	b.names["PID"] = "PID (raw)"
 
+	b.defaults["Target"] = "Local"
+	b.defaults["LocalUART"] = "9600 8N1"
+	b.defaults["Module"] = E22.DEFAULT_MODEL.Name()
+	b.defaults["Retries"] = "3"
+	b.defaults["RetryBackoff"] = "100"
 	b.defaults["ADDH"] = "0"
 	b.defaults["ADDL"] = "0"
 	b.defaults["UARTRate"] = "9600"
 	b.defaults["UARTParityBit"] = "8N1"
+	b.defaults["IODrive"] = "Push-pull"
 	b.defaults["NETID"] = "0"
 	b.defaults["WirelessRate"] = "2400"
 	b.defaults["SubPacketLength"] = "240"
-	b.defaults["Power"] = "30"
-	b.defaults["Channel"] = "433"
 	b.defaults["TransmissionMode"] = "Transparent"
 	b.defaults["WOR"] = "Receiver"
-	b.defaults["WORCycle"] = "500"
 	b.defaults["CryptH"] = "0"
 	b.defaults["CryptL"] = "0"
 	b.defaults["PID"] = ""
 
+	b.selectOptions["Target"] = []string{"Local", "Remote (wireless)"}
+	b.selectOptions["LocalUART"] = []string{"9600 8N1", "19200 8N1", "38400 8N1", "57600 8N1", "115200 8N1"}
+	b.selectOptions["Module"] = []string{AUTODETECT}
+	for _, item := range LoRa.Models() {
+		b.selectOptions["Module"] = append(b.selectOptions["Module"], item.Name())
+	}
 	b.selectOptions["UARTRate"] = []string{"1200", "2400", "4800", "9600", "19200", "38400", "57600", "115200"}
 	b.selectOptions["UARTParityBit"] = []string{"8N1", "8O1", "8E1"}
-	b.selectOptions["WirelessRate"] = []string{"300", "1200", "2400", "4800", "9600", "19200", "38400", "62500"}
+	b.selectOptions["IODrive"] = []string{"Push-pull", "Open collector"}
 	b.selectOptions["SubPacketLength"] = []string{"240", "128", "64", "32"}
-	b.selectOptions["Power"] = []string{"30", "27", "24", "21"}
 	ports, err := serial.GetPortsList()
 	if err == nil && len(ports) > 0 {
 		b.selectOptions["Device"] = ports
@@ -137,334 +190,607 @@ func NewBTLP() *BTLP {
 		b.defaults["Device"] = "/dev/tty.usbserial-0001"
 		b.selectOptions["Device"] = []string{"/dev/tty.usbserial-0001"}
 	}
-	data := []string{}
-	for i := 0; i < 84; i++ {
-		data = append(data, fmt.Sprintf("%d", i + 410))
-	}
-	b.selectOptions["Channel"] = data
+	b.setModelOptions(model)
 	b.selectOptions["TransmissionMode"] = []string{"Fixed point", "Transparent"}
 	b.selectOptions["WOR"] = []string{"Transmitter", "Receiver"}
-	data = []string{}
-	for i := 1; i < 9; i++ {
-		data = append(data, fmt.Sprintf("%d", i * 500))
-	}
-	b.selectOptions["WORCycle"] = data
 
 	b.Buttons["read"] = widget.NewButton("Read", func() {
-		var err error
-		dev := boot.selects["Device"].Text
-		log.Println("Trying to open " + dev)
-		Serial = NewSerialPort(dev)
-		if err = Serial.Open(); err == nil {
-			TryCatchBlock {
-		    Try: func() {
-					boot.DisableButtons()
-					boot.SetState("Port " + dev + " opened")
-					data, err := Serial.Command(E22.COMMAND_GET_REGISTER[:], E22.GET_CONFIG[:])
-					if len(data) == 10 {
-						args := data[3:]
-						log.Printf("ARGS: %s", hex.Dump(args))
-						boot.entries["ADDH"].SetText(fmt.Sprintf("%d", args[E22.REGISTER_ADDH[0]]))
-						boot.entries["ADDL"].SetText(fmt.Sprintf("%d", args[E22.REGISTER_ADDL[0]]))
-						boot.entries["NETID"].SetText(fmt.Sprintf("%d", args[E22.REGISTER_NETID[0]]))
-						switch args[E22.REGISTER_REG0[0]] & E22.MASK_UART_BAUD {
-						case E22.UART_BAUD_1200:
-							boot.selects["UARTRate"].SetText("1200")
-						case E22.UART_BAUD_2400:
-							boot.selects["UARTRate"].SetText("2400")
-						case E22.UART_BAUD_4800:
-							boot.selects["UARTRate"].SetText("4800")
-						case E22.UART_BAUD_9600:
-							boot.selects["UARTRate"].SetText("9600")
-						case E22.UART_BAUD_19200:
-							boot.selects["UARTRate"].SetText("19200")
-						case E22.UART_BAUD_38400:
-							boot.selects["UARTRate"].SetText("38400")
-						case E22.UART_BAUD_57600:
-							boot.selects["UARTRate"].SetText("57600")
-						case E22.UART_BAUD_115200:
-							boot.selects["UARTRate"].SetText("115200")
-						default:
-							Throw("Unknown UARTRate value!")
-						}
-						switch args[E22.REGISTER_REG0[0]] & E22.MASK_UART_PARITY {
-						case E22.UART_8N1:
-							boot.selects["UARTParityBit"].SetText("8N1")
-						case E22.UART_8O1:
-							boot.selects["UARTParityBit"].SetText("8O1")
-						case E22.UART_8E1:
-							boot.selects["UARTParityBit"].SetText("8E1")
-						default:
-							Throw("Unknown UARTParityBit value!")
-						}
-						switch args[E22.REGISTER_REG0[0]] & E22.MASK_AIR_BAUD {
-						case E22.AIR_BAUD_300:
-							boot.selects["WirelessRate"].SetText("300")
-						case E22.AIR_BAUD_1200:
-							boot.selects["WirelessRate"].SetText("1200")
-						case E22.AIR_BAUD_2400:
-							boot.selects["WirelessRate"].SetText("2400")
-						case E22.AIR_BAUD_4800:
-							boot.selects["WirelessRate"].SetText("4800")
-						case E22.AIR_BAUD_9600:
-							boot.selects["WirelessRate"].SetText("9600")
-						case E22.AIR_BAUD_19200:
-							boot.selects["WirelessRate"].SetText("19200")
-						case E22.AIR_BAUD_38400:
-							boot.selects["WirelessRate"].SetText("38400")
-						case E22.AIR_BAUD_62500:
-							boot.selects["WirelessRate"].SetText("62500")
-						default:
-							Throw("Unknown WirelessRate value!")
-						}
-						switch args[E22.REGISTER_REG1[0]] & E22.MASK_SUB_PACKET {
-						case E22.SUB_PACKET_BYTES_240:
-							boot.selects["SubPacketLength"].SetText("240")
-						case E22.SUB_PACKET_BYTES_128:
-							boot.selects["SubPacketLength"].SetText("128")
-						case E22.SUB_PACKET_BYTES_64:
-							boot.selects["SubPacketLength"].SetText("64")
-						case E22.SUB_PACKET_BYTES_32:
-							boot.selects["SubPacketLength"].SetText("32")
-						default:
-							Throw("Unknown SubPacketLength value!")
-						}
-						switch args[E22.REGISTER_REG1[0]] & E22.MASK_AMBIENT_NOISE {
-						case E22.AMBIENT_NOISE_ENABLE:
-							boot.checks["AmbientNoise"].SetChecked(true)
-						case E22.AMBIENT_NOISE_DISABLE:
-							boot.checks["AmbientNoise"].SetChecked(false)
-						default:
-							Throw("Unknown AmbientNoise value!")
-						}
-						switch args[E22.REGISTER_REG1[0]] & E22.MASK_POWER {
-						case E22.POWER_DBM_30:
-							boot.selects["Power"].SetText("30")
-						case E22.POWER_DBM_27:
-							boot.selects["Power"].SetText("27")
-						case E22.POWER_DBM_24:
-							boot.selects["Power"].SetText("24")
-						case E22.POWER_DBM_21:
-							boot.selects["Power"].SetText("21")
-						default:
-							Throw("Unknown Power value!")
-						}
-						boot.selects["Channel"].SetText(fmt.Sprintf("%d", int(args[E22.REGISTER_REG2[0]]) + 410 ))
-						switch args[E22.REGISTER_REG3[0]] & E22.MASK_RSSI {
-						case E22.RSSI_ENABLE:
-							boot.checks["RSSI"].SetChecked(true)
-						case E22.RSSI_DISABLE:
-							boot.checks["RSSI"].SetChecked(false)
-						default:
-							Throw("Unknown RSSI value!")
-						}
-						switch args[E22.REGISTER_REG3[0]] & E22.MASK_TRANSMISSION_MODE {
-						case E22.TRANSMISSION_MODE_FIXED:
-							boot.selects["TransmissionMode"].SetText("Fixed point")
-						case E22.TRANSMISSION_MODE_TRANSPARENT:
-							boot.selects["TransmissionMode"].SetText("Transparent")
-						default:
-							Throw("Unknown TransmissionMode value!")
-						}
-						switch args[E22.REGISTER_REG3[0]] & E22.MASK_REPEATER {
-						case E22.REPEATER_ENABLE:
-							boot.checks["Repeater"].SetChecked(true)
-						case E22.REPEATER_DISABLE:
-							boot.checks["Repeater"].SetChecked(false)
-						default:
-							Throw("Unknown Repeater value!")
-						}
-						switch args[E22.REGISTER_REG3[0]] & E22.MASK_LBT {
-						case E22.LBT_ENABLE:
-							boot.checks["LBT"].SetChecked(true)
-						case E22.LBT_DISABLE:
-							boot.checks["LBT"].SetChecked(false)
-						default:
-							Throw("Unknown LBT value!")
-						}
-						switch args[E22.REGISTER_REG3[0]] & E22.MASK_WOR_CONTROL {
-						case E22.WOR_TRANSMITTER:
-							boot.selects["WOR"].SetText("Transmitter")
-						case E22.WOR_RECEIVER:
-							boot.selects["WOR"].SetText("Receiver")
-						default:
-							Throw("Unknown WOR value!")
-						}
-						boot.selects["WORCycle"].SetText(fmt.Sprintf("%d", (int(args[E22.REGISTER_REG3[0]] & E22.MASK_WOR_CYCLE) + 1) * 500 ))
-						boot.SetState("Reading DONE")
-					} else {
-						logError("Reading", err)
-					}
-					data, err = Serial.Command(E22.COMMAND_GET_REGISTER[:], E22.GET_PRODUCT_INFO[:])
-					if err == nil {
-						boot.labels["PID"].SetText(hex.Dump(data[3:]))
-					} else {
-						logError("Reading", err)
-					}
-				},
-				Catch: func(e Exception) {
-					log.Printf("%v\n", e)
-					logError("Writing", fmt.Errorf("%v", e))
-				},
-				Finally: func() {
-					Serial.Close()
-					boot.EnableButtons()
-				},
-			}.Do()
-		} else {
-			logError("Port opening", err)
-		}
+		boot.ReadConfig()
 	})
 	b.Buttons["write"] = widget.NewButton("Write", func() {
-		var err error
-		dev := boot.selects["Device"].Text
-		log.Println("Trying to open " + dev)
-		Serial = NewSerialPort(dev)
-		if err = Serial.Open(); err == nil {
-		  TryCatchBlock {
-		    Try: func() {
-					boot.DisableButtons()
-					boot.SetState("Port " + dev + " opened")
-					args := [9]byte{}
-					args[E22.REGISTER_ADDH[0]] = s2b(boot.entries["ADDH"].Text)
-					args[E22.REGISTER_ADDL[0]] = s2b(boot.entries["ADDL"].Text)
-					args[E22.REGISTER_NETID[0]] = s2b(boot.entries["NETID"].Text)
-					switch boot.selects["UARTRate"].Text {
-					case "1200":
-						args[E22.REGISTER_REG0[0]] |= E22.UART_BAUD_1200
-					case "2400":
-						args[E22.REGISTER_REG0[0]] |= E22.UART_BAUD_2400
-					case "4800":
-						args[E22.REGISTER_REG0[0]] |= E22.UART_BAUD_4800
-					case "9600":
-						args[E22.REGISTER_REG0[0]] |= E22.UART_BAUD_9600
-					case "19200":
-						args[E22.REGISTER_REG0[0]] |= E22.UART_BAUD_19200
-					case "38400":
-						args[E22.REGISTER_REG0[0]] |= E22.UART_BAUD_38400
-					case "57600":
-						args[E22.REGISTER_REG0[0]] |= E22.UART_BAUD_57600
-					case "115200":
-						args[E22.REGISTER_REG0[0]] |= E22.UART_BAUD_115200
-					default:
-						Throw("Unknown UARTRate value!")
-					}
-					switch boot.selects["UARTParityBit"].Text {
-					case "8N1":
-						args[E22.REGISTER_REG0[0]] |= E22.UART_8N1
-					case "8O1":
-						args[E22.REGISTER_REG0[0]] |= E22.UART_8O1
-					case "8E1":
-						args[E22.REGISTER_REG0[0]] |= E22.UART_8E1
-					default:
-						Throw("Unknown UARTParityBit value!")
-					}
-					switch boot.selects["WirelessRate"].Text {
-					case "300":
-						args[E22.REGISTER_REG0[0]] |= E22.AIR_BAUD_300
-					case "1200":
-						args[E22.REGISTER_REG0[0]] |= E22.AIR_BAUD_1200
-					case "2400":
-						args[E22.REGISTER_REG0[0]] |= E22.AIR_BAUD_2400
-					case "4800":
-						args[E22.REGISTER_REG0[0]] |= E22.AIR_BAUD_4800
-					case "9600":
-						args[E22.REGISTER_REG0[0]] |= E22.AIR_BAUD_9600
-					case "19200":
-						args[E22.REGISTER_REG0[0]] |= E22.AIR_BAUD_19200
-					case "38400":
-						args[E22.REGISTER_REG0[0]] |= E22.AIR_BAUD_38400
-					case "62500":
-						args[E22.REGISTER_REG0[0]] |= E22.AIR_BAUD_62500
-					default:
-						Throw("Unknown WirelessRate value!")
-					}
-					switch boot.selects["SubPacketLength"].Text {
-					case "240":
-						args[E22.REGISTER_REG1[0]] |= E22.SUB_PACKET_BYTES_240
-					case "128":
-						args[E22.REGISTER_REG1[0]] |= E22.SUB_PACKET_BYTES_128
-					case "64":
-						args[E22.REGISTER_REG1[0]] |= E22.SUB_PACKET_BYTES_64
-					case "32":
-						args[E22.REGISTER_REG1[0]] |= E22.SUB_PACKET_BYTES_32
-					default:
-						Throw("Unknown SubPacketLength value!")
-					}
-					if boot.checks["AmbientNoise"].Checked {
-						args[E22.REGISTER_REG1[0]] |= E22.AMBIENT_NOISE_ENABLE
-					}
-					switch boot.selects["Power"].Text {
-					case "30":
-						args[E22.REGISTER_REG1[0]] |= E22.POWER_DBM_30
-					case "27":
-						args[E22.REGISTER_REG1[0]] |= E22.POWER_DBM_27
-					case "24":
-						args[E22.REGISTER_REG1[0]] |= E22.POWER_DBM_24
-					case "21":
-						args[E22.REGISTER_REG1[0]] |= E22.POWER_DBM_21
-					default:
-						Throw("Unknown Power value!")
-					}
-					channel := getInt(boot.selects["Channel"].Text) - 410
-					if channel < 0 || channel > 83 {
-						Throw("Unknown Channel value!")
-					}
-					args[E22.REGISTER_REG2[0]] = byte(channel)
-					if boot.checks["RSSI"].Checked {
-						args[E22.REGISTER_REG3[0]] |= E22.RSSI_ENABLE
-					}
-					switch boot.selects["TransmissionMode"].Text {
-					case "Fixed point":
-						args[E22.REGISTER_REG3[0]] |= E22.TRANSMISSION_MODE_FIXED
-					case "Transparent":
-						args[E22.REGISTER_REG3[0]] |= E22.TRANSMISSION_MODE_TRANSPARENT
-					default:
-						Throw("Unknown TransmissionMode value!")
-					}
-					if boot.checks["Repeater"].Checked {
-						args[E22.REGISTER_REG3[0]] |= E22.REPEATER_ENABLE
-					}
-					if boot.checks["LBT"].Checked {
-						args[E22.REGISTER_REG3[0]] |= E22.LBT_ENABLE
-					}
-					switch boot.selects["WOR"].Text {
-					case "Transmitter":
-						args[E22.REGISTER_REG3[0]] |= E22.WOR_TRANSMITTER
-					case "Receiver":
-						args[E22.REGISTER_REG3[0]] |= E22.WOR_RECEIVER
-					default:
-						Throw("Unknown WOR value!")
-					}
-					cycle := byte((getInt(boot.selects["WORCycle"].Text) / 500) - 1)
-					if cycle > E22.WOR_CYCLE_MS_4000 {
-						Throw("Unknown WORCycle value!")
-					}
-					args[E22.REGISTER_REG3[0]] |= cycle
-					args[E22.REGISTER_CRYPT_H[0]] = s2b(boot.entries["CryptH"].Text)
-					args[E22.REGISTER_CRYPT_L[0]] = s2b(boot.entries["CryptL"].Text)
-					data, err := Serial.Command(E22.COMMAND_SET_REGISTER[:], E22.SET_CONFIG[:], args[:])
-					if err != nil {
-						logError("Writing", err)
-					} else {
-						log.Printf("WRITE: %s", hex.Dump(data[3:]))
-						boot.SetState("Writing DONE")
-					}
-		    },
-		    Catch: func(e Exception) {
-		      log.Printf("%v\n", e)
-		      logError("Writing", fmt.Errorf("%v", e))
-		    },
-				Finally: func() {
-					Serial.Close()
-					boot.EnableButtons()
-				},
-		  }.Do()
-		}
+		boot.WriteConfig(false)
+	})
+	b.Buttons["apply"] = widget.NewButton("Apply (not saved)", func() {
+		boot.WriteConfig(true)
 	})
 	return b
 }
 
+// ReadConfig reads module settings and product information into the form
+func (x *BTLP) ReadConfig() {
+	TryCatchBlock {
+		Try: func() {
+			x.DisableButtons()
+			m := x.ActiveModel()
+			switch m.Family() {
+			case "E22":
+				x.OpenE22(m)
+				cfg, err := lora.ReadConfig()
+				if err == nil {
+					x.SetConfig(cfg)
+					x.SetState("Reading DONE")
+				} else {
+					logError("Reading", err)
+				}
+				info, err := lora.ReadProductInfo()
+				if err == nil {
+					x.SetProductInfo(info)
+				} else {
+					logError("Reading", err)
+				}
+			default:
+				x.OpenE32(m)
+				cfg, err := lora32.ReadConfig()
+				if err == nil {
+					x.SetE32Config(cfg)
+					x.SetState("Reading DONE")
+				} else {
+					logError("Reading", err)
+				}
+				version, err := lora32.ReadVersion()
+				if err == nil {
+					x.SetVersion(version)
+				} else {
+					logError("Reading", err)
+				}
+			}
+		},
+		Catch: func(e Exception) {
+			log.Printf("%v\n", e)
+			logError("Reading", fmt.Errorf("%v", e))
+		},
+		Finally: func() {
+			closeDevices()
+			x.EnableButtons()
+		},
+	}.Do()
+}
+
+// WriteConfig writes form settings to the module; temporary settings
+// are not saved and lost on module power cycle
+func (x *BTLP) WriteConfig(temporary bool) {
+	TryCatchBlock {
+		Try: func() {
+			var err error
+			x.DisableButtons()
+			m := x.ActiveModel()
+			switch m.Family() {
+			case "E22":
+				x.OpenE22(m)
+				if temporary {
+					err = lora.ApplyConfig(x.Config())
+				} else {
+					err = lora.WriteConfig(x.Config())
+				}
+			default:
+				x.OpenE32(m)
+				if temporary {
+					err = lora32.ApplyConfig(x.E32Config())
+				} else {
+					err = lora32.WriteConfig(x.E32Config())
+				}
+			}
+			if err != nil {
+				logError("Writing", err)
+			} else if temporary {
+				x.SetState("Applying DONE (not saved)")
+			} else {
+				x.SetState("Writing DONE")
+			}
+		},
+		Catch: func(e Exception) {
+			log.Printf("%v\n", e)
+			logError("Writing", fmt.Errorf("%v", e))
+		},
+		Finally: func() {
+			closeDevices()
+			x.EnableButtons()
+		},
+	}.Do()
+}
+
+// OpenPort opens selected serial device or network transport given as URL
+func (x *BTLP) OpenPort() LoRa.Transport {
+	dev := strings.TrimSpace(x.selects["Device"].Text)
+	log.Println("Trying to open " + dev)
+	if Transport.IsURL(dev) {
+		t, err := Transport.Dial(dev)
+		if err != nil {
+			Throw(fmt.Sprintf("Port opening FAILED: %v", err))
+		}
+		x.SetState("Port " + dev + " opened")
+		link = &LoggedTransport{t}
+		return x.recorded(link)
+	}
+	Serial = NewSerialPort(dev)
+	if err := Serial.Open(); err != nil {
+		Throw(fmt.Sprintf("Port opening FAILED: %v", err))
+	}
+	x.SetState("Port " + dev + " opened")
+	link = Serial
+	return x.recorded(link)
+}
+
+// annotate describes captured chunk as E22 traffic
+func annotate(entry Transport.Entry) string {
+	return E22.Annotate(entry.Data, entry.Direction == Transport.DIRECTION_TX)
+}
+
+// recorded wraps transport by recorder if capture file is given
+func (x *BTLP) recorded(t LoRa.Transport) LoRa.Transport {
+	if capture == "" {
+		return t
+	}
+	r, err := Transport.RecordFile(t, capture, annotate)
+	if err != nil {
+		t.Close()
+		Throw(fmt.Sprintf("Capture opening FAILED: %v", err))
+	}
+	return r
+}
+
+// SetUARTMode switches opened port to UART settings of the form, which
+// module uses outside configuration mode; raw TCP ports are left as is
+func (x *BTLP) SetUARTMode() {
+	x.setPortMode(getInt(x.selects["UARTRate"].Text), x.selects["UARTParityBit"].Text)
+}
+
+// SetLocalUARTMode switches opened port to UART settings of the local
+// module, which relays wireless configuration in normal mode
+func (x *BTLP) SetLocalUARTMode() {
+	fields := strings.Fields(x.selects["LocalUART"].Text)
+	if len(fields) != 2 {
+		Throw("Unknown LocalUART value!")
+	}
+	x.setPortMode(getInt(fields[0]), fields[1])
+}
+
+func (x *BTLP) setPortMode(rate int, parity string) {
+	mode, err := Transport.UARTMode(rate, parity)
+	if err != nil {
+		Throw(err.Error())
+	}
+	target := link
+	if logged, ok := link.(*LoggedTransport); ok {
+		target = logged.Transport
+	}
+	port, ok := target.(Transport.Configurable)
+	if !ok {
+		log.Println("UART settings of the port can't be changed, they are kept as is")
+		return
+	}
+	if err := port.SetMode(mode); err != nil {
+		Throw(fmt.Sprintf("UART settings FAILED: %v", err))
+	}
+}
+
+// OpenE22 prepares E22 driver for the model
+func (x *BTLP) OpenE22(m LoRa.Model) {
+	lora.Model = m.(*E22.Model)
+	lora.Wireless = x.Wireless()
+	lora.Retry = x.RetryPolicy()
+	lora.Pins = x.OpenPins()
+	lora.Aux = x.OpenAux()
+	x.OpenDevice(&lora.Port)
+	if lora.Wireless {
+		x.SetLocalUARTMode()
+	}
+}
+
+// OpenE32 prepares E32 driver for the model (E31 shares its protocol)
+func (x *BTLP) OpenE32(m LoRa.Model) {
+	if x.Wireless() {
+		Throw(fmt.Sprintf("Wireless configuration is not supported by %s", m.Family()))
+	}
+	lora32.Model = m.(*E32.Model)
+	lora32.Retry = x.RetryPolicy()
+	lora32.Pins = x.OpenPins()
+	lora32.Aux = x.OpenAux()
+	x.OpenDevice(&lora32.Port)
+}
+
+// OpenDevice attaches selected port to the driver; the port is closed if
+// the driver is still busy (e.g. by RSSI monitor)
+func (x *BTLP) OpenDevice(port *LoRa.Port) {
+	t := x.OpenPort()
+	if err := port.Open(t); err != nil {
+		t.Close()
+		Throw(fmt.Sprintf("Port opening FAILED: %v", err))
+	}
+}
+
+// OpenPins requests M0/M1 (and optional AUX) GPIO lines given as
+// "chip:M0,M1[,AUX]", e.g. "gpiochip0:17,27,22"; modes are set by jumpers
+// if nothing is given
+func (x *BTLP) OpenPins() LoRa.ModeController {
+	if pins != nil {
+		return pins
+	}
+	text := strings.TrimSpace(x.entries["ModePins"].Text)
+	if text == "" {
+		return nil
+	}
+	parts := strings.SplitN(text, ":", 2)
+	lines := strings.Split(parts[len(parts) - 1], ",")
+	if len(parts) != 2 || len(lines) < 2 || len(lines) > 3 {
+		Throw("Unknown ModePins value!")
+	}
+	chip := parts[0]
+	if !strings.HasPrefix(chip, "/") {
+		chip = "/dev/" + chip
+	}
+	p, err := GPIO.NewModePins(chip, uint32(getInt(lines[0])), uint32(getInt(lines[1])))
+	if err != nil {
+		Throw(fmt.Sprintf("Mode pins opening FAILED: %v", err))
+	}
+	if len(lines) == 3 {
+		a, err := GPIO.NewAuxPin(chip, uint32(getInt(lines[2])))
+		if err != nil {
+			p.Close()
+			Throw(fmt.Sprintf("AUX pin opening FAILED: %v", err))
+		}
+		aux = a
+		p.Aux = aux
+	}
+	pins = p
+	return pins
+}
+
+// OpenAux returns AUX pin given with mode pins, nil if it is not wired
+func (x *BTLP) OpenAux() LoRa.AuxMonitor {
+	if x.OpenPins() == nil || aux == nil {
+		return nil
+	}
+	return aux
+}
+
+// RetryPolicy returns command retry settings of the form
+func (x *BTLP) RetryPolicy() LoRa.RetryPolicy {
+	policy := LoRa.RetryPolicy{
+		Attempts: getInt(strings.TrimSpace(x.entries["Retries"].Text)),
+		Backoff: time.Duration(getInt(strings.TrimSpace(x.entries["RetryBackoff"].Text))) * time.Millisecond,
+		MaxBackoff: RETRY_MAX_BACKOFF,
+	}
+	if x.checks["RetryRejected"].Checked {
+		policy.Errors = []error{E22.WrongFormat}
+	}
+	return policy
+}
+
+// closeDevices closes drivers, their ports and GPIO lines
+func closeDevices() {
+	if monitor.running {
+		// RSSI monitor owns the port until it's stopped
+		return
+	}
+	lora.Close()
+	lora32.Close()
+	if pins != nil {
+		pins.Close()
+		pins = nil
+	}
+	if aux != nil {
+		aux.Close()
+		aux = nil
+	}
+}
+
+// ActiveModel resolves selected model, detecting it if needed
+func (x *BTLP) ActiveModel() LoRa.Model {
+	name := x.selects["Module"].Text
+	if name != AUTODETECT {
+		m, err := LoRa.Find(name)
+		if err != nil {
+			Throw(err)
+		}
+		x.SelectModel(m)
+		return m
+	}
+	candidates := x.DetectModel()
+	names := []string{}
+	for _, m := range candidates {
+		names = append(names, m.Name())
+	}
+	log.Printf("Model detected: %s", strings.Join(names, " or "))
+	if len(candidates) == 1 {
+		x.SelectModel(candidates[0])
+		return candidates[0]
+	}
+	// module doesn't tell the variant, the last model is kept if it fits
+	for _, m := range candidates {
+		if m == model {
+			x.SetState("WARNING: %s can't be told apart, %s is used", strings.Join(names, ", "), model.Name())
+			return model
+		}
+	}
+	Throw(fmt.Sprintf("Model detection is ambiguous (%s), select the model", strings.Join(names, ", ")))
+	return nil
+}
+
+// familyModels returns registered models of the family
+func familyModels(family string) []LoRa.Model {
+	list := []LoRa.Model{}
+	for _, m := range LoRa.Models() {
+		if m.Family() == family {
+			list = append(list, m)
+		}
+	}
+	return list
+}
+
+// DetectModel asks module for product information (E22) or version (E31/E32)
+// and returns models it may be
+func (x *BTLP) DetectModel() []LoRa.Model {
+	lora.Model = nil
+	lora.Wireless = x.Wireless()
+	lora.Retry = x.RetryPolicy()
+	lora.Pins = x.OpenPins()
+	lora.Aux = x.OpenAux()
+	x.OpenDevice(&lora.Port)
+	if lora.Wireless {
+		x.SetLocalUARTMode()
+	}
+	info, err := lora.ReadProductInfo()
+	lora.Close()
+	if err == nil {
+		// product information layout isn't documented, it tells the family only
+		log.Printf("E22 product information: % X", info.Raw)
+		return familyModels("E22")
+	}
+	log.Printf("E22 product information not available: %v", err)
+	if x.Wireless() {
+		Throw(fmt.Sprintf("Model detection failed: %v", err))
+	}
+	lora32.Model = nil
+	lora32.Retry = x.RetryPolicy()
+	lora32.Pins = x.OpenPins()
+	lora32.Aux = x.OpenAux()
+	x.OpenDevice(&lora32.Port)
+	version, err := lora32.ReadVersion()
+	lora32.Close()
+	if err != nil {
+		Throw(fmt.Sprintf("Model detection failed: %v", err))
+	}
+	list := []LoRa.Model{}
+	for _, m := range version.Models() {
+		list = append(list, m)
+	}
+	if len(list) == 0 {
+		Throw(fmt.Sprintf("Model detection failed: unknown version % X", version.Frequency))
+	}
+	return list
+}
+
+// SelectModel makes model active and updates its options in the form
+func (x *BTLP) SelectModel(m LoRa.Model) {
+	if m == model {
+		return
+	}
+	model = m
+	x.setModelOptions(m)
+	for _, name := range []string{"WirelessRate", "Power", "Channel", "WORCycle"} {
+		entry := x.selects[name]
+		entry.SetOptions(x.selectOptions[name])
+		if name == "Channel" {
+			if _, err := planChannel(m.Plan(), entry.Text); err == nil {
+				continue
+			}
+		}
+		if optionIndex(x.selectOptions[name], entry.Text) < 0 {
+			entry.SetText(x.defaults[name])
+		}
+	}
+}
+
+// SelectedChannel returns channel nearest to the frequency selected or typed in the form
+func (x *BTLP) SelectedChannel() byte {
+	channel, err := planChannel(model.Plan(), x.selects["Channel"].Text)
+	if err != nil {
+		Throw(err.Error())
+	}
+	return channel
+}
+
+// planChannel parses frequency in MHz and finds its channel in the plan
+func planChannel(plan LoRa.FrequencyPlan, text string) (byte, error) {
+	frequency, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+	if err != nil {
+		return 0, &LoRa.ConfigError{Field: "Frequency", Value: text}
+	}
+	return plan.Channel(frequency)
+}
+
+// setModelOptions fills model dependent options and defaults
+func (x *BTLP) setModelOptions(m LoRa.Model) {
+	data := []string{}
+	for _, rate := range m.AirRates() {
+		data = append(data, fmt.Sprintf("%d", rate))
+	}
+	x.selectOptions["WirelessRate"] = data
+	data = []string{}
+	for _, power := range m.Powers() {
+		data = append(data, fmt.Sprintf("%d", power))
+	}
+	x.selectOptions["Power"] = data
+	x.defaults["Power"] = data[0]
+	plan := m.Plan()
+	data = []string{}
+	for i := 0; i < plan.Channels; i++ {
+		frequency, _ := plan.Frequency(byte(i))
+		data = append(data, strconv.FormatFloat(frequency, 'f', -1, 64))
+	}
+	x.selectOptions["Channel"] = data
+	x.defaults["Channel"] = data[plan.DefaultChannel]
+	data = []string{}
+	for _, cycle := range m.WORCycles() {
+		data = append(data, fmt.Sprintf("%d", cycle))
+	}
+	x.selectOptions["WORCycle"] = data
+	x.defaults["WORCycle"] = data[0]
+}
+
+// Wireless reports if remote module is configured over the air
+func (x *BTLP) Wireless() bool {
+	switch x.selects["Target"].Text {
+	case "Local":
+		return false
+	case "Remote (wireless)":
+		return true
+	default:
+		Throw("Unknown Target value!")
+	}
+	return false
+}
+
+// SetProductInfo shows module identity in the form
+func (x *BTLP) SetProductInfo(info E22.ProductInfo) {
+	// layout of product information isn't documented, only raw PID is shown
+	for _, name := range []string{"Model", "Family", "Band", "MaxPower", "Firmware"} {
+		x.labels[name].SetText("")
+	}
+	x.labels["PID"].SetText(hex.EncodeToString(info.Raw))
+}
+
+// SetVersion shows E31/E32 module version in the form
+func (x *BTLP) SetVersion(version E32.Version) {
+	x.labels["Model"].SetText(model.Name())
+	x.labels["Family"].SetText(model.Family())
+	x.labels["Band"].SetText(fmt.Sprintf("%d", version.Band()))
+	x.labels["MaxPower"].SetText(fmt.Sprintf("%d", model.Powers()[0]))
+	x.labels["Firmware"].SetText(fmt.Sprintf("%d", version.Version))
+	x.labels["PID"].SetText(fmt.Sprintf("%02x%02x%02x", version.Frequency, version.Version, version.Features))
+}
+
+// SetE32Config shows E31/E32 module settings in the form
+func (x *BTLP) SetE32Config(cfg E32.Config) {
+	x.entries["ADDH"].SetText(b2s(byte(cfg.Address >> 8)))
+	x.entries["ADDL"].SetText(b2s(byte(cfg.Address)))
+	x.selects["UARTRate"].SetText(fmt.Sprintf("%d", cfg.UARTRate))
+	x.selects["UARTParityBit"].SetText(cfg.UARTParity)
+	if cfg.IOPushPull {
+		x.selects["IODrive"].SetText("Push-pull")
+	} else {
+		x.selects["IODrive"].SetText("Open collector")
+	}
+	x.selects["WirelessRate"].SetText(fmt.Sprintf("%d", cfg.AirRate))
+	x.selects["Power"].SetText(fmt.Sprintf("%d", cfg.Power))
+	x.selects["Channel"].SetText(x.selectOptions["Channel"][cfg.Channel])
+	if cfg.FixedTransmission {
+		x.selects["TransmissionMode"].SetText("Fixed point")
+	} else {
+		x.selects["TransmissionMode"].SetText("Transparent")
+	}
+	x.checks["FEC"].SetChecked(cfg.FEC)
+	x.selects["WORCycle"].SetText(fmt.Sprintf("%d", cfg.WakeUpTime))
+}
+
+// E32Config collects E31/E32 module settings from the form
+func (x *BTLP) E32Config() E32.Config {
+	cfg := E32.Config{}
+	cfg.Address = uint16(s2b(x.entries["ADDH"].Text)) << 8 | uint16(s2b(x.entries["ADDL"].Text))
+	cfg.UARTRate = getInt(x.selects["UARTRate"].Text)
+	cfg.UARTParity = x.selects["UARTParityBit"].Text
+	switch x.selects["IODrive"].Text {
+	case "Push-pull":
+		cfg.IOPushPull = true
+	case "Open collector":
+		cfg.IOPushPull = false
+	default:
+		Throw("Unknown IODrive value!")
+	}
+	cfg.AirRate = getInt(x.selects["WirelessRate"].Text)
+	cfg.Power = getInt(x.selects["Power"].Text)
+	cfg.Channel = x.SelectedChannel()
+	switch x.selects["TransmissionMode"].Text {
+	case "Fixed point":
+		cfg.FixedTransmission = true
+	case "Transparent":
+		cfg.FixedTransmission = false
+	default:
+		Throw("Unknown TransmissionMode value!")
+	}
+	cfg.FEC = x.checks["FEC"].Checked
+	cfg.WakeUpTime = getInt(x.selects["WORCycle"].Text)
+	return cfg
+}
+
+// SetConfig shows module settings in the form
+func (x *BTLP) SetConfig(cfg E22.Config) {
+	x.entries["ADDH"].SetText(b2s(byte(cfg.Address >> 8)))
+	x.entries["ADDL"].SetText(b2s(byte(cfg.Address)))
+	x.entries["NETID"].SetText(b2s(cfg.NetID))
+	x.selects["UARTRate"].SetText(fmt.Sprintf("%d", cfg.UARTRate))
+	x.selects["UARTParityBit"].SetText(cfg.UARTParity)
+	x.selects["WirelessRate"].SetText(fmt.Sprintf("%d", cfg.AirRate))
+	x.selects["SubPacketLength"].SetText(fmt.Sprintf("%d", cfg.SubPacket))
+	x.checks["AmbientNoise"].SetChecked(cfg.AmbientNoise)
+	x.selects["Power"].SetText(fmt.Sprintf("%d", cfg.Power))
+	if int(cfg.Channel) >= len(x.selectOptions["Channel"]) {
+		Throw("Unknown Channel value!")
+	}
+	x.selects["Channel"].SetText(x.selectOptions["Channel"][cfg.Channel])
+	x.checks["RSSI"].SetChecked(cfg.RSSI)
+	if cfg.FixedTransmission {
+		x.selects["TransmissionMode"].SetText("Fixed point")
+	} else {
+		x.selects["TransmissionMode"].SetText("Transparent")
+	}
+	x.checks["Repeater"].SetChecked(cfg.Repeater)
+	x.checks["LBT"].SetChecked(cfg.LBT)
+	if cfg.WORTransmitter {
+		x.selects["WOR"].SetText("Transmitter")
+	} else {
+		x.selects["WOR"].SetText("Receiver")
+	}
+	x.selects["WORCycle"].SetText(fmt.Sprintf("%d", cfg.WORCycle))
+}
+
+// Config collects module settings from the form
+func (x *BTLP) Config() E22.Config {
+	cfg := E22.Config{}
+	cfg.Address = uint16(s2b(x.entries["ADDH"].Text)) << 8 | uint16(s2b(x.entries["ADDL"].Text))
+	cfg.NetID = s2b(x.entries["NETID"].Text)
+	cfg.UARTRate = getInt(x.selects["UARTRate"].Text)
+	cfg.UARTParity = x.selects["UARTParityBit"].Text
+	cfg.AirRate = getInt(x.selects["WirelessRate"].Text)
+	cfg.SubPacket = getInt(x.selects["SubPacketLength"].Text)
+	cfg.AmbientNoise = x.checks["AmbientNoise"].Checked
+	cfg.Power = getInt(x.selects["Power"].Text)
+	cfg.Channel = x.SelectedChannel()
+	cfg.RSSI = x.checks["RSSI"].Checked
+	switch x.selects["TransmissionMode"].Text {
+	case "Fixed point":
+		cfg.FixedTransmission = true
+	case "Transparent":
+		cfg.FixedTransmission = false
+	default:
+		Throw("Unknown TransmissionMode value!")
+	}
+	cfg.Repeater = x.checks["Repeater"].Checked
+	cfg.LBT = x.checks["LBT"].Checked
+	switch x.selects["WOR"].Text {
+	case "Transmitter":
+		cfg.WORTransmitter = true
+	case "Receiver":
+		cfg.WORTransmitter = false
+	default:
+		Throw("Unknown WOR value!")
+	}
+	cfg.WORCycle = getInt(x.selects["WORCycle"].Text)
+	cfg.CryptKey = uint16(s2b(x.entries["CryptH"].Text)) << 8 | uint16(s2b(x.entries["CryptL"].Text))
+	return cfg
+}
+
 func (x *BTLP) SetState(format string, args ...interface{}) {
 	text := fmt.Sprintf(format, args...)
 	x.labels["State"].SetText(text)
@@ -520,6 +846,12 @@ func (x *BTLP) DisableButtons() {
 	}
 	x.Buttons["read"].Disable()
 	x.Buttons["write"].Disable()
+	x.Buttons["apply"].Disable()
+	x.Buttons["registers"].Disable()
+	x.Buttons["rssi"].Disable()
+	for _, item := range x.registerCells {
+		item.Disable()
+	}
 	x.Progress.Show()
 }
 
@@ -535,6 +867,12 @@ func (x *BTLP) EnableButtons() {
 	}
 	x.Buttons["read"].Enable()
 	x.Buttons["write"].Enable()
+	x.Buttons["apply"].Enable()
+	x.Buttons["registers"].Enable()
+	x.Buttons["rssi"].Enable()
+	for _, item := range x.registerCells {
+		item.Enable()
+	}
 	x.Progress.Hide()
 }
 
@@ -542,6 +880,16 @@ func b2s(data byte) string {
 	return fmt.Sprintf("%v", data)
 }
 
+// optionIndex returns position of value in options or -1
+func optionIndex(options []string, value string) int {
+	for i, item := range options {
+		if item == value {
+			return i
+		}
+	}
+	return -1
+}
+
 func s2b(str string) byte {
 	if str == "" {
 		Throw(makeError(fmt.Errorf("uint not parsed"), FileLine()).Error())
@@ -563,13 +911,16 @@ func addTabItem(win fyne.Window, name, heading string, read, write bool) *contai
 		readButton = boot.Buttons["read"]
 	}
 	writeButton := layout.NewSpacer()
+	applyButton := layout.NewSpacer()
 	if write {
 		writeButton = boot.Buttons["write"]
+		applyButton = boot.Buttons["apply"]
 	}
 	buttons := container.NewHBox(
 		layout.NewSpacer(),
 		readButton,
 		writeButton,
+		applyButton,
 		layout.NewSpacer(),
 	)
 	return container.NewTabItem(heading,
@@ -613,6 +964,11 @@ func Show(win fyne.Window) fyne.CanvasObject {
 		boot.Progress,
 		layout.NewSpacer(),
 	)
+	boot.selects["Module"].OnChanged = func(name string) {
+		if m, err := LoRa.Find(name); err == nil {
+			boot.SelectModel(m)
+		}
+	}
 	border := container.NewBorder(nil, nil, nil, nil,
 		container.NewAppTabs(
 			addTabItem(win, "address", "Address", true, true),
@@ -621,6 +977,8 @@ func Show(win fyne.Window) fyne.CanvasObject {
 			addTabItem(win, "wor", "WOR", true, true),
 			addTabItem(win, "crypto", "Cryptography", false, true),
 			addTabItem(win, "product", "Product Information", true, false),
+			addRegistersTab(win),
+			addRSSITab(win),
 		))
 	box := container.NewVBox(
 		states,
@@ -634,15 +992,48 @@ func Show(win fyne.Window) fyne.CanvasObject {
 func createGUI() fyne.Window {
 	log.Println("Starting GUI...")
 	a := app.New()
-	w := a.NewWindow("E22-400T30D Module Configuration Utility")
+	w := a.NewWindow("EBYTE E22/E31/E32 Module Configuration Utility")
 	w.SetContent(Show(w))
 	w.Resize(fyne.NewSize(width, 200))
 	return w
 }
 
+// startSimulator exposes simulated module on a pseudo terminal
+func startSimulator(name string) *Simulator.PTY {
+	m, err := LoRa.Find(name)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	e22, ok := m.(*E22.Model)
+	if !ok {
+		log.Fatalf("Only E22 modules can be simulated, %s given", name)
+	}
+	pty, err := Simulator.ServePTY(Simulator.New(e22))
+	if err != nil {
+		log.Fatalf("Simulator starting FAILED: %v", err)
+	}
+	log.Printf("Simulated %s is on %s", name, pty.Path)
+	return pty
+}
+
 func main() {
+	retries := flag.Int("retries", 3, "number of attempts of each module command")
+	backoff := flag.Int("backoff", 100, "delay before the first retry (ms), doubled for each next one")
+	rejected := flag.Bool("retry-rejected", false, "retry commands rejected by module (FF FF FF)")
+	simulate := flag.String("simulate", "", "expose simulated E22 module of given model on a pseudo terminal")
+	flag.StringVar(&capture, "record", "", "append port traffic to capture file: .pcapng, .pcap or text for replay:///path/to/file")
+	flag.Parse()
 	rand.Seed(time.Now().Unix())
 	boot = NewBTLP()
+	boot.defaults["Retries"] = strconv.Itoa(*retries)
+	boot.defaults["RetryBackoff"] = strconv.Itoa(*backoff)
+	if *simulate != "" {
+		pty := startSimulator(*simulate)
+		defer pty.Close()
+		boot.selectOptions["Device"] = append([]string{pty.Path}, boot.selectOptions["Device"]...)
+		boot.defaults["Device"] = pty.Path
+	}
 	window := createGUI()
+	boot.checks["RetryRejected"].SetChecked(*rejected)
 	window.ShowAndRun()
 }
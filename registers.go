@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"e22config/LoRa/E22"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+// addRegistersTab makes raw register editor of E22 module
+func addRegistersTab(win fyne.Window) *container.TabItem {
+	// empty range reads all readable registers of the register map
+	start := widget.NewEntry()
+	start.SetPlaceHolder("readable registers")
+	count := widget.NewEntry()
+	count.SetPlaceHolder("readable registers")
+	boot.entries["RegisterStart"] = start
+	boot.entries["RegisterCount"] = count
+	form := &widget.Form{}
+	form.Append("Start address (hex, optional)", start)
+	form.Append("Number of registers (optional)", count)
+	boot.registers = container.NewGridWithColumns(3)
+	boot.Buttons["registers"] = widget.NewButton("Read", func() {
+		boot.ReadRegisters(start.Text, count.Text)
+	})
+	buttons := container.NewHBox(
+		layout.NewSpacer(),
+		boot.Buttons["registers"],
+		layout.NewSpacer(),
+	)
+	return container.NewTabItem("Registers",
+		container.NewBorder(form, buttons, nil, nil,
+			container.NewVScroll(boot.registers)),
+	)
+}
+
+// ReadRegisters reads register range, readable ranges of the register map
+// if the range is empty, and shows them as hex grid
+func (x *BTLP) ReadRegisters(start, count string) {
+	TryCatchBlock {
+		Try: func() {
+			x.DisableButtons()
+			ranges := E22.ReadableRanges()
+			if strings.TrimSpace(start) != "" || strings.TrimSpace(count) != "" {
+				ranges = []E22.RegisterRange{{Address: h2b(start), Length: s2b(count)}}
+			}
+			m := x.ActiveModel()
+			if m.Family() != "E22" {
+				Throw(fmt.Sprintf("Register access is not supported by %s", m.Family()))
+			}
+			x.OpenE22(m)
+			blocks := [][]byte{}
+			for _, item := range ranges {
+				data, err := lora.ReadRegisters(item.Address, item.Length)
+				if err != nil {
+					logError("Reading", err)
+					return
+				}
+				blocks = append(blocks, data)
+			}
+			x.ShowRegisters(ranges, blocks)
+			x.SetState("Reading DONE")
+		},
+		Catch: func(e Exception) {
+			log.Printf("%v\n", e)
+			logError("Reading", fmt.Errorf("%v", e))
+		},
+		Finally: func() {
+			closeDevices()
+			x.EnableButtons()
+		},
+	}.Do()
+}
+
+// WriteRegister writes a single register value given in hex
+func (x *BTLP) WriteRegister(address byte, value string) {
+	TryCatchBlock {
+		Try: func() {
+			x.DisableButtons()
+			data := h2b(value)
+			m := x.ActiveModel()
+			if m.Family() != "E22" {
+				Throw(fmt.Sprintf("Register access is not supported by %s", m.Family()))
+			}
+			x.OpenE22(m)
+			if err := lora.WriteRegisters(address, []byte{data}); err != nil {
+				logError("Writing", err)
+				return
+			}
+			x.SetState("Writing %02XH DONE", address)
+		},
+		Catch: func(e Exception) {
+			log.Printf("%v\n", e)
+			logError("Writing", fmt.Errorf("%v", e))
+		},
+		Finally: func() {
+			closeDevices()
+			x.EnableButtons()
+		},
+	}.Do()
+}
+
+// ShowRegisters fills hex grid: register, value, write-back button
+func (x *BTLP) ShowRegisters(ranges []E22.RegisterRange, blocks [][]byte) {
+	objects := []fyne.CanvasObject{}
+	x.registerCells = nil
+	for n, data := range blocks {
+		for i, value := range data {
+			register := ranges[n].Address + byte(i)
+			name := strings.TrimSpace(fmt.Sprintf("%02XH %s", register, E22.RegisterName(register)))
+			cell := widget.NewEntry()
+			cell.SetText(fmt.Sprintf("%02X", value))
+			write := widget.NewButton("Write", func() {
+				x.WriteRegister(register, cell.Text)
+			})
+			objects = append(objects, widget.NewLabel(name), cell, write)
+			x.registerCells = append(x.registerCells, cell, write)
+		}
+	}
+	x.registers.Objects = objects
+	x.registers.Refresh()
+}
+
+func h2b(str string) byte {
+	value, err := strconv.ParseUint(strings.TrimSpace(str), 16, 8)
+	if err != nil {
+		Throw(makeError(fmt.Errorf("hex byte not parsed: %s", str), FileLine()).Error())
+	}
+	return byte(value)
+}
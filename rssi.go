@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	RSSI_POLL_MS = 500
+	RSSI_MIN_DBM = -128
+)
+
+// RSSIMonitor polls module noise and last packet RSSI into gauges
+type RSSIMonitor struct {
+	Stoppable
+	noise *widget.ProgressBar
+	last  *widget.ProgressBar
+	running bool
+}
+
+var (
+	monitor RSSIMonitor
+)
+
+func newGauge() *widget.ProgressBar {
+	gauge := widget.NewProgressBar()
+	gauge.Min = RSSI_MIN_DBM
+	gauge.Max = 0
+	gauge.SetValue(RSSI_MIN_DBM)
+	gauge.TextFormatter = func() string {
+		return fmt.Sprintf("%.0f dBm", gauge.Value)
+	}
+	return gauge
+}
+
+// addRSSITab makes live noise/RSSI panel of E22 module
+func addRSSITab(win fyne.Window) *container.TabItem {
+	monitor.noise = newGauge()
+	monitor.last = newGauge()
+	form := &widget.Form{}
+	form.Append("Channel noise", monitor.noise)
+	form.Append("Last packet RSSI", monitor.last)
+	form.Append("", widget.NewLabel("Requires Ambient Noise enabled on the Wireless tab"))
+	boot.Buttons["rssi"] = widget.NewButton("Start", func() {
+		if monitor.running {
+			monitor.Stop()
+		} else {
+			boot.StartRSSI()
+		}
+	})
+	buttons := container.NewHBox(
+		layout.NewSpacer(),
+		boot.Buttons["rssi"],
+		layout.NewSpacer(),
+	)
+	return container.NewTabItem("RSSI",
+		container.NewBorder(form, buttons, nil, nil),
+	)
+}
+
+// StartRSSI opens E22 module and starts polling it
+func (x *BTLP) StartRSSI() {
+	TryCatchBlock {
+		Try: func() {
+			x.DisableButtons()
+			m := x.ActiveModel()
+			if m.Family() != "E22" {
+				Throw(fmt.Sprintf("RSSI readout is not supported by %s", m.Family()))
+			}
+			if x.Wireless() {
+				Throw("RSSI of remote module can't be read")
+			}
+			x.OpenE22(m)
+			// module answers at its own UART settings in normal mode
+			x.SetUARTMode()
+			if err := monitor.Start(&monitor, "RSSI monitor", RSSI_POLL_MS); err != nil {
+				Throw(err.Error())
+			}
+		},
+		Catch: func(e Exception) {
+			log.Printf("%v\n", e)
+			logError("RSSI monitor", fmt.Errorf("%v", e))
+		},
+		Finally: func() {
+			if !monitor.running {
+				closeDevices()
+				x.EnableButtons()
+			}
+		},
+	}.Do()
+}
+
+func (m *RSSIMonitor) Construct() error {
+	m.running = true
+	boot.Buttons["rssi"].SetText("Stop")
+	boot.Buttons["rssi"].Enable()
+	boot.SetState("RSSI monitor started")
+	return nil
+}
+
+func (m *RSSIMonitor) Do() error {
+	rssi, err := lora.ReadRSSI()
+	if err != nil {
+		return err
+	}
+	// 0 is no reading, gauge keeps the last one
+	if rssi.Noise != 0 {
+		m.noise.SetValue(float64(rssi.Noise))
+	}
+	if rssi.Last != 0 {
+		m.last.SetValue(float64(rssi.Last))
+	}
+	return nil
+}
+
+func (m *RSSIMonitor) Destruct() error {
+	m.running = false
+	closeDevices()
+	boot.Buttons["rssi"].SetText("Start")
+	boot.EnableButtons()
+	boot.SetState("RSSI monitor stopped")
+	return nil
+}
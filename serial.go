@@ -2,10 +2,7 @@ package main
 
 import (
   "fmt"
-	"time"
   "log"
-  "context"
-  "errors"
   "encoding/hex"
   "go.bug.st/serial.v1"
 )
@@ -18,7 +15,6 @@ type SerialPort struct {
 
 var (
 	Serial *SerialPort
-  NoResponse = errors.New("No response from device")
 )
 
 func (s *SerialPort) Open() error {
@@ -27,61 +23,40 @@ func (s *SerialPort) Open() error {
   return err
 }
 
-func (s *SerialPort) Write(data []byte) error {
+func (s *SerialPort) Write(data []byte) (int, error) {
   if s.port == nil {
-    return makeError(fmt.Errorf("port is nil"), FileLine())
+    return 0, makeError(fmt.Errorf("port is nil"), FileLine())
   }
-  _, err := s.port.Write(data)
-  return err
-}
-
-type SerialResponse struct {
-  Data []byte
-  Err error
-}
-
-func (s *SerialPort) Command(data ...[]byte) ([]byte, error) {
-  cmd := []byte{}
-  for _, item := range data {
-    cmd = append(cmd, item...)
+  n, err := s.port.Write(data)
+  if n > 0 {
+    log.Printf("<--- (%d bytes) %s", n, hex.Dump(data[:n]))
   }
-
-  channel := make(chan SerialResponse)
-  ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-	defer cancel()
-
-  go func() {
-    if err := s.Write(cmd); err != nil {
-      channel <- SerialResponse{[]byte{}, NoResponse}
-      return
-    }
-    log.Printf("<--- (%d bytes) %s", len(cmd), hex.Dump(cmd))
-    time.Sleep(500 * time.Millisecond)
-    res := SerialResponse{}
-    res.Data, res.Err = s.Read()
-    channel <- res
-  }()
-
-  select {
-  case result := <-channel:
-    return result.Data, result.Err
-	case <-ctx.Done():
-    return []byte{}, NoResponse
-	}
-  return []byte{}, NoResponse
+  return n, err
 }
 
-func (s *SerialPort) Read() ([]byte, error) {
+// Read is called by the only reader goroutine of LoRa.Port, which also
+// serves commands with their timeouts and expected lengths
+func (s *SerialPort) Read(buffer []byte) (int, error) {
   if s.port == nil {
-    return []byte{}, makeError(fmt.Errorf("port is nil"), FileLine())
+    return 0, makeError(fmt.Errorf("port is nil"), FileLine())
   }
-  buffer := make([]byte, 1024)
   n, err := s.port.Read(buffer)
-  if err == nil && n > 0 {
+  if n > 0 {
     log.Printf("===> (%d bytes) %s", n, hex.Dump(buffer[:n]))
-    return buffer[:n], nil
   }
-  return []byte{}, err
+  return n, err
+}
+
+// SetMode changes UART settings of open port
+func (s *SerialPort) SetMode(mode *serial.Mode) error {
+  if s.port == nil {
+    return makeError(fmt.Errorf("port is nil"), FileLine())
+  }
+  if err := s.port.SetMode(mode); err != nil {
+    return err
+  }
+  s.Config = *mode
+  return nil
 }
 
 func (s *SerialPort) Close() error {
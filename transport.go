@@ -0,0 +1,28 @@
+package main
+
+import (
+  "encoding/hex"
+  "log"
+  "e22config/LoRa"
+)
+
+// LoggedTransport dumps traffic of any transport the way SerialPort does
+type LoggedTransport struct {
+  LoRa.Transport
+}
+
+func (t *LoggedTransport) Write(data []byte) (int, error) {
+  n, err := t.Transport.Write(data)
+  if n > 0 {
+    log.Printf("<--- (%d bytes) %s", n, hex.Dump(data[:n]))
+  }
+  return n, err
+}
+
+func (t *LoggedTransport) Read(buffer []byte) (int, error) {
+  n, err := t.Transport.Read(buffer)
+  if n > 0 {
+    log.Printf("===> (%d bytes) %s", n, hex.Dump(buffer[:n]))
+  }
+  return n, err
+}